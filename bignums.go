@@ -0,0 +1,203 @@
+// Copyright 2019 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rapid
+
+import (
+	"fmt"
+	"math/big"
+)
+
+const (
+	bigIntMaxBits   = 1024
+	bigRatMaxBits   = 256
+	bigFloatMaxExp  = 4096
+	bigEdgeCaseProb = 0.15
+)
+
+var (
+	bigOne      = big.NewInt(1)
+	bigIntLimit = new(big.Int).Sub(new(big.Int).Lsh(bigOne, bigIntMaxBits), bigOne)
+)
+
+// BigInt is a shorthand for [BigIntRange](-(2^1024-1), 2^1024-1).
+func BigInt() *Generator[*big.Int] {
+	return newGenerator[*big.Int](&bigIntGen{
+		min: new(big.Int).Neg(bigIntLimit),
+		max: bigIntLimit,
+		str: "BigInt()",
+	})
+}
+
+// BigIntBits creates a generator of non-negative integers less than 2^n.
+func BigIntBits(n int) *Generator[*big.Int] {
+	assertf(n >= 0, "invalid number of bits %v", n)
+
+	return newGenerator[*big.Int](&bigIntGen{
+		min: new(big.Int),
+		max: new(big.Int).Sub(new(big.Int).Lsh(bigOne, uint(n)), bigOne),
+		str: fmt.Sprintf("BigIntBits(%v)", n),
+	})
+}
+
+// BigIntRange creates a generator of integers in range [min, max].
+// Generated integers are biased towards small magnitudes, powers of two
+// and 64-bit word boundaries, and shrink towards zero.
+func BigIntRange(min *big.Int, max *big.Int) *Generator[*big.Int] {
+	assertf(min.Cmp(max) <= 0, "invalid range [%v, %v]", min, max)
+
+	return newGenerator[*big.Int](&bigIntGen{
+		min: new(big.Int).Set(min),
+		max: new(big.Int).Set(max),
+		str: fmt.Sprintf("BigIntRange(%v, %v)", min, max),
+	})
+}
+
+type bigIntGen struct {
+	min *big.Int
+	max *big.Int
+	str string
+}
+
+func (g *bigIntGen) String() string {
+	return g.str
+}
+
+func (g *bigIntGen) value(t *T) *big.Int {
+	return genBigIntRange(t.s, g.min, g.max)
+}
+
+// BigRat creates a generator of rational numbers with numerators and denominators
+// of at most 256 bits. Generated numbers shrink towards zero.
+func BigRat() *Generator[*big.Rat] {
+	return newGenerator[*big.Rat](&bigRatGen{})
+}
+
+type bigRatGen struct{}
+
+func (g *bigRatGen) String() string {
+	return "BigRat()"
+}
+
+func (g *bigRatGen) value(t *T) *big.Rat {
+	limit := new(big.Int).Sub(new(big.Int).Lsh(bigOne, bigRatMaxBits), bigOne)
+	num := genBigIntRange(t.s, new(big.Int).Neg(limit), limit)
+	den := genBigIntRange(t.s, bigOne, limit)
+
+	return new(big.Rat).SetFrac(num, den)
+}
+
+// BigFloat creates a generator of finite floating-point numbers with precision prec,
+// and binary exponents in range [-4096, 4096]. Generated numbers shrink towards zero.
+func BigFloat(prec uint) *Generator[*big.Float] {
+	assertf(prec > 0 && prec <= big.MaxPrec, "invalid precision %v", prec)
+
+	return newGenerator[*big.Float](&bigFloatGen{
+		prec: prec,
+	})
+}
+
+type bigFloatGen struct {
+	prec uint
+}
+
+func (g *bigFloatGen) String() string {
+	return fmt.Sprintf("BigFloat(%v)", g.prec)
+}
+
+func (g *bigFloatGen) value(t *T) *big.Float {
+	limit := new(big.Int).Sub(new(big.Int).Lsh(bigOne, g.prec), bigOne)
+	mant := genBigIntRange(t.s, new(big.Int).Neg(limit), limit)
+	exp, _, _ := genIntRange(t.s, -bigFloatMaxExp, bigFloatMaxExp, true)
+
+	f := new(big.Float).SetPrec(g.prec).SetInt(mant)
+	return f.SetMantExp(f, int(exp)-mant.BitLen())
+}
+
+func genBigIntRange(s bitStream, min *big.Int, max *big.Int) *big.Int {
+	switch {
+	case min.Sign() >= 0:
+		u := genBigUintN(s, new(big.Int).Sub(max, min))
+		return u.Add(u, min)
+	case max.Sign() <= 0:
+		u := genBigUintN(s, new(big.Int).Sub(max, min))
+		return u.Sub(max, u)
+	default:
+		if flipBiasedCoin(s, 0.5) {
+			u := genBigUintN(s, new(big.Int).Sub(new(big.Int).Neg(min), bigOne))
+			return u.Neg(u.Add(u, bigOne))
+		}
+		return genBigUintN(s, max)
+	}
+}
+
+// genBigUintN generates an integer in range [0, max], drawing the words
+// most significant first, for the integers to shrink towards zero word-by-word.
+func genBigUintN(s bitStream, max *big.Int) *big.Int {
+	maxBits := max.BitLen()
+	n, _, _ := genUintN(s, uint64(maxBits), true)
+	bits := int(n)
+
+	var u *big.Int
+	for {
+		i := s.beginGroup(intBitsLabel, false)
+		u = genBigBits(s, bits)
+		ok := u.Cmp(max) <= 0
+		s.endGroup(i, !ok)
+		if ok {
+			break
+		}
+	}
+
+	if flipBiasedCoin(s, bigEdgeCaseProb) {
+		u = nearestBigEdgeCase(u, max)
+	}
+
+	return u
+}
+
+func genBigBits(s bitStream, n int) *big.Int {
+	words := make([]big.Word, (n+uintSize-1)/uintSize)
+	if len(words) == 0 {
+		s.drawBits(0)
+	}
+	for i := len(words) - 1; i >= 0; i-- {
+		w := uintSize
+		if i == len(words)-1 && n%uintSize != 0 {
+			w = n % uintSize
+		}
+		words[i] = big.Word(s.drawBits(w))
+	}
+
+	return new(big.Int).SetBits(words)
+}
+
+// nearestBigEdgeCase snaps u to the nearest power of two (±1), with the powers
+// at 64-bit word boundaries considered as well. Snapping (instead of choosing
+// a random edge case) keeps the result shrinkable.
+func nearestBigEdgeCase(u *big.Int, max *big.Int) *big.Int {
+	l := u.BitLen()
+	best := new(big.Int).Set(max)
+	bestDist := new(big.Int).Sub(max, u)
+	for _, k := range []int{l - 1, l, l / 64 * 64, (l + 63) / 64 * 64} {
+		if k < 0 {
+			continue
+		}
+		p := new(big.Int).Lsh(bigOne, uint(k))
+		for _, c := range []*big.Int{new(big.Int).Sub(p, bigOne), p, new(big.Int).Add(p, bigOne)} {
+			if c.Cmp(max) > 0 {
+				continue
+			}
+			d := new(big.Int).Sub(c, u)
+			d.Abs(d)
+			if d.Cmp(bestDist) < 0 {
+				best, bestDist = c, d
+			}
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,124 @@
+// Copyright 2019 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rapid_test
+
+import (
+	"math/big"
+	"testing"
+
+	. "pgregory.net/rapid"
+)
+
+func TestBigExamples(t *testing.T) {
+	gens := []*Generator[any]{
+		BigInt().AsAny(),
+		BigIntBits(128).AsAny(),
+		BigIntRange(big.NewInt(-10), big.NewInt(1000)).AsAny(),
+		BigRat().AsAny(),
+		BigFloat(100).AsAny(),
+	}
+
+	for _, g := range gens {
+		t.Run(g.String(), func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				t.Log(g.Example())
+			}
+		})
+	}
+}
+
+func TestBigIntRange(t *testing.T) {
+	t.Parallel()
+
+	Check(t, func(t *T) {
+		min := BigInt().Draw(t, "min")
+		max := BigInt().Draw(t, "max")
+		if min.Cmp(max) > 0 {
+			min, max = max, min
+		}
+
+		i := BigIntRange(min, max).Draw(t, "i")
+		if i.Cmp(min) < 0 || i.Cmp(max) > 0 {
+			t.Fatalf("got %v outside of [%v, %v]", i, min, max)
+		}
+	})
+}
+
+func TestBigIntBits(t *testing.T) {
+	t.Parallel()
+
+	Check(t, func(t *T) {
+		n := IntRange(0, 300).Draw(t, "n")
+		i := BigIntBits(n).Draw(t, "i")
+		if i.Sign() < 0 || i.BitLen() > n {
+			t.Fatalf("got %v with %v bits, more than %v", i, i.BitLen(), n)
+		}
+	})
+}
+
+func TestBigIntEdgeCases(t *testing.T) {
+	t.Parallel()
+
+	g := BigIntBits(256)
+	pow64 := new(big.Int).Lsh(big.NewInt(1), 64)
+	var gotPow2, gotWord bool
+	for i := 0; i < 10000 && !(gotPow2 && gotWord); i++ {
+		v := g.Example(i)
+		if v.BitLen() > 8 {
+			prev := new(big.Int).Sub(v, big.NewInt(1))
+			gotPow2 = gotPow2 || new(big.Int).And(v, prev).Sign() == 0
+		}
+		w := g.Example(i)
+		gotWord = gotWord || w.Cmp(pow64) == 0 || w.Cmp(new(big.Int).Sub(pow64, big.NewInt(1))) == 0
+	}
+	if !gotPow2 || !gotWord {
+		t.Fatalf("edge cases not generated: powers of two %v, word boundaries %v", gotPow2, gotWord)
+	}
+}
+
+func TestBigRat(t *testing.T) {
+	t.Parallel()
+
+	Check(t, func(t *T) {
+		r := BigRat().Draw(t, "r")
+		if r.Denom().Sign() <= 0 || r.Denom().BitLen() > 256 {
+			t.Fatalf("got %v with invalid denominator", r)
+		}
+	})
+}
+
+func TestBigFloat(t *testing.T) {
+	t.Parallel()
+
+	Check(t, func(t *T) {
+		prec := UintRange(1, 300).Draw(t, "prec")
+		f := BigFloat(prec).Draw(t, "f")
+		if f.Prec() != prec {
+			t.Fatalf("got %v with precision %v instead of %v", f, f.Prec(), prec)
+		}
+		if f.IsInf() {
+			t.Fatalf("got infinite %v", f)
+		}
+	})
+}
+
+func TestMakeBig(t *testing.T) {
+	t.Parallel()
+
+	type amount struct {
+		Int   *big.Int
+		Rat   *big.Rat
+		Float *big.Float
+	}
+
+	Check(t, func(t *T) {
+		a := Make[amount]().Draw(t, "a")
+		if a.Int == nil || a.Rat == nil || a.Float == nil {
+			t.Fatalf("got nil field in %+v", a)
+		}
+	})
+}
@@ -6,7 +6,11 @@
 
 package rapid
 
-import "fmt"
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
 
 // ID returns its argument as is. ID is a helper for use with [SliceOfDistinct] and similar functions.
 func ID[V any](v V) V {
@@ -192,3 +196,147 @@ func (g *mapGen[K, V]) value(t *T) map[K]V {
 
 	return m
 }
+
+// SubsetOf is a shorthand for [SubsetOfN](slice, -1, -1).
+func SubsetOf[S ~[]E, E any](slice S) *Generator[S] {
+	return SubsetOfN(slice, -1, -1)
+}
+
+// SubsetOfN creates a generator of subsequences of the given slice, which preserve
+// the order of elements. If minLen >= 0, generated subsequences have minimum length of minLen.
+// If maxLen >= 0, generated subsequences have maximum length of maxLen. SubsetOfN panics
+// if maxLen >= 0 and minLen > maxLen, or if minLen > len(slice).
+// Generated subsequences shrink towards fewer elements from the start of the slice.
+func SubsetOfN[S ~[]E, E any](slice S, minLen int, maxLen int) *Generator[S] {
+	assertValidRange(minLen, maxLen)
+	assertf(minLen <= len(slice), "minLen %v is greater than the number of elements %v", minLen, len(slice))
+
+	return newGenerator[S](&subsetGen[S, E]{
+		slice:  slice,
+		minLen: minLen,
+		maxLen: maxLen,
+	})
+}
+
+type subsetGen[S ~[]E, E any] struct {
+	slice  S
+	minLen int
+	maxLen int
+}
+
+func (g *subsetGen[S, E]) String() string {
+	var zero E
+	if g.minLen < 0 && g.maxLen < 0 {
+		return fmt.Sprintf("SubsetOf(%v %T)", len(g.slice), zero)
+	} else {
+		return fmt.Sprintf("SubsetOfN(%v %T, minLen=%v, maxLen=%v)", len(g.slice), zero, g.minLen, g.maxLen)
+	}
+}
+
+func (g *subsetGen[S, E]) value(t *T) S {
+	ixs := genSubsequence(t.s, len(g.slice), g.minLen, g.maxLen)
+
+	s := make(S, len(ixs))
+	for i, ix := range ixs {
+		s[i] = g.slice[ix]
+	}
+
+	return s
+}
+
+// SubmapOf creates a generator of maps with subsets of the keys of m (and the same values).
+// Generated maps shrink towards fewer keys, with keys ordered by value for basic types,
+// or by their %#v representation otherwise.
+func SubmapOf[M ~map[K]V, K comparable, V any](m M) *Generator[M] {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sortKeys(keys)
+
+	return newGenerator[M](&submapGen[M, K, V]{
+		m:    m,
+		keys: keys,
+	})
+}
+
+type submapGen[M ~map[K]V, K comparable, V any] struct {
+	m    M
+	keys []K
+}
+
+func (g *submapGen[M, K, V]) String() string {
+	var zero M
+	return fmt.Sprintf("SubmapOf(%v %T)", len(g.m), zero)
+}
+
+func (g *submapGen[M, K, V]) value(t *T) M {
+	ixs := genSubsequence(t.s, len(g.keys), -1, -1)
+
+	m := make(M, len(ixs))
+	for _, ix := range ixs {
+		k := g.keys[ix]
+		m[k] = g.m[k]
+	}
+
+	return m
+}
+
+// genSubsequence generates increasing indices in [0, n). Each index is drawn in its own
+// repeat group, so that removing the group removes just the corresponding element.
+func genSubsequence(s bitStream, n int, minLen int, maxLen int) []int {
+	if maxLen < 0 || maxLen > n {
+		maxLen = n
+	}
+
+	repeat := newRepeat(minLen, maxLen, -1, "subset")
+	used := make([]bool, n)
+	ixs := make([]int, 0, repeat.avg())
+	for repeat.more(s) {
+		ix := genIndex(s, n, false)
+		if used[ix] {
+			if repeat.count > minLen {
+				repeat.reject()
+				continue
+			}
+			for used[ix] { // required elements take the next unused index, to never run out of rejections
+				ix = (ix + 1) % n
+			}
+		}
+		used[ix] = true
+		ixs = append(ixs, ix)
+	}
+	sort.Ints(ixs)
+
+	return ixs
+}
+
+// sortKeys sorts keys of basic types by value, and keys of other types by their %#v representation.
+func sortKeys[K comparable](keys []K) {
+	less := func(a, b reflect.Value) bool {
+		if a.Kind() != b.Kind() { // possible for interface keys
+			return a.Kind() < b.Kind()
+		}
+
+		switch a.Kind() {
+		case reflect.Invalid:
+			return false
+		case reflect.Bool:
+			return !a.Bool() && b.Bool()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return a.Uint() < b.Uint()
+		case reflect.Float32, reflect.Float64:
+			return a.Float() < b.Float()
+		case reflect.String:
+			return a.String() < b.String()
+		default:
+			return fmt.Sprintf("%#v", a.Interface()) < fmt.Sprintf("%#v", b.Interface())
+		}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return less(reflect.ValueOf(keys[i]), reflect.ValueOf(keys[j]))
+	})
+}
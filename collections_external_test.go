@@ -140,3 +140,60 @@ func TestCollectionLenLimits(t *testing.T) {
 		}))
 	}
 }
+
+func TestSubsetOfN(t *testing.T) {
+	t.Parallel()
+
+	Check(t, func(t *T) {
+		n := IntRange(0, 20).Draw(t, "n")
+		slice := make([]int, n)
+		for i := range slice {
+			slice[i] = i
+		}
+		minLen := IntRange(-1, n).Draw(t, "minLen")
+		maxLen := IntRange(-1, n+5).Draw(t, "maxLen")
+		if maxLen >= 0 && minLen > maxLen {
+			minLen, maxLen = maxLen, minLen
+		}
+
+		s := SubsetOfN(slice, minLen, maxLen).Draw(t, "s")
+		if len(s) < minLen || (maxLen >= 0 && len(s) > maxLen) {
+			t.Fatalf("got subset of length %v outside of [%v, %v]", len(s), minLen, maxLen)
+		}
+		for i := 1; i < len(s); i++ {
+			if s[i] <= s[i-1] {
+				t.Fatalf("got subset %v which is not an ordered subsequence", s)
+			}
+		}
+	})
+}
+
+func TestSubsetOfElements(t *testing.T) {
+	t.Parallel()
+
+	slice := []string{"a", "b", "c", "d", "e"}
+	seen := map[string]bool{}
+	Check(t, func(t *T) {
+		for _, s := range SubsetOf(slice).Draw(t, "s") {
+			seen[s] = true
+		}
+	})
+
+	if len(seen) != len(slice) {
+		t.Fatalf("only %v of %v elements have been generated", len(seen), len(slice))
+	}
+}
+
+func TestSubmapOf(t *testing.T) {
+	t.Parallel()
+
+	m := map[string]int{"a": 1, "b": 2, "c": 3, "d": 4}
+	Check(t, func(t *T) {
+		sub := SubmapOf(m).Draw(t, "sub")
+		for k, v := range sub {
+			if m[k] != v {
+				t.Fatalf("got key %q with value %v instead of %v", k, v, m[k])
+			}
+		}
+	})
+}
@@ -10,9 +10,14 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"sync"
 )
 
-const tryLabel = "try"
+const (
+	tryLabel            = "try"
+	bindLabel           = "bind"
+	recursiveExtendProb = 0.75
+)
 
 // Custom creates a generator which produces results of calling fn. In fn, values should be generated
 // by calling other generators; it is invalid to return a value from fn without using any other generator.
@@ -37,7 +42,9 @@ func (g *customGen[V]) value(t *T) V {
 }
 
 func (g *customGen[V]) maybeValue(t *T) (V, bool) {
+	cfg, stats, leaves := t.cfg, t.stats, t.leaves
 	t = newT(t.tb, t.s, flags.debug, nil)
+	t.cfg, t.stats, t.leaves = cfg, stats, leaves
 	defer t.cleanup()
 
 	defer func() {
@@ -76,6 +83,76 @@ func (g *deferredGen[V]) value(t *T) V {
 	return g.g.value(t)
 }
 
+// Recursive creates a generator of recursive values, like trees. Values are either produced
+// by base, or by the generator returned from extend, which should use its argument
+// to generate the nested values. Once maxLeaves values of base have been generated,
+// values are no longer extended. The probability of extending a value decreases
+// as leaves are generated, so that nested values tend to get smaller with depth.
+// Generated values shrink towards the values of base.
+func Recursive[V any](base *Generator[V], extend func(*Generator[V]) *Generator[V], maxLeaves int) *Generator[V] {
+	assertf(maxLeaves > 0, "maxLeaves should be positive")
+
+	g := &recursiveGen[V]{
+		base:      base,
+		extend:    extend,
+		maxLeaves: maxLeaves,
+	}
+	g.child = newGenerator[V](&recursiveChildGen[V]{g})
+
+	return newGenerator[V](g)
+}
+
+type recursiveGen[V any] struct {
+	base      *Generator[V]
+	extend    func(*Generator[V]) *Generator[V]
+	maxLeaves int
+	child     *Generator[V]
+	once      sync.Once
+	extended  *Generator[V]
+}
+
+func (g *recursiveGen[V]) String() string {
+	return fmt.Sprintf("Recursive(%v, maxLeaves=%v)", g.base, g.maxLeaves)
+}
+
+func (g *recursiveGen[V]) value(t *T) V {
+	if _, ok := t.leaves[g]; ok {
+		return g.node(t)
+	}
+
+	if t.leaves == nil {
+		t.leaves = map[any]int{}
+	}
+	t.leaves[g] = g.maxLeaves
+	defer delete(t.leaves, g)
+
+	return g.node(t)
+}
+
+func (g *recursiveGen[V]) node(t *T) V {
+	left := t.leaves[g]
+	if left > 0 && flipBiasedCoin(t.s, recursiveExtendProb*float64(left)/float64(g.maxLeaves)) {
+		g.once.Do(func() { g.extended = g.extend(g.child) })
+		return g.extended.value(t)
+	}
+
+	t.leaves[g] = left - 1
+	return g.base.value(t)
+}
+
+type recursiveChildGen[V any] struct {
+	g *recursiveGen[V]
+}
+
+func (g *recursiveChildGen[V]) String() string {
+	var v V
+	return fmt.Sprintf("Recursive(%T)", v)
+}
+
+func (g *recursiveChildGen[V]) value(t *T) V {
+	return g.g.node(t)
+}
+
 func filter[V any](g *Generator[V], fn func(V) bool) *Generator[V] {
 	return newGenerator[V](&filteredGen[V]{
 		g:  g,
@@ -140,6 +217,35 @@ func (g *mappedGen[U, V]) value(t *T) V {
 	return g.fn(g.g.value(t))
 }
 
+// Bind creates a generator producing values from fn(u) for each u produced by g.
+// Bind allows the generator of the values to depend on a previously generated value.
+// When shrinking, u is shrunk first, with the dependent value generated anew.
+func Bind[U any, V any](g *Generator[U], fn func(U) *Generator[V]) *Generator[V] {
+	return newGenerator[V](&boundGen[U, V]{
+		g:  g,
+		fn: fn,
+	})
+}
+
+type boundGen[U any, V any] struct {
+	g  *Generator[U]
+	fn func(U) *Generator[V]
+}
+
+func (g *boundGen[U, V]) String() string {
+	return fmt.Sprintf("Bind(%v, %T)", g.g, g.fn)
+}
+
+func (g *boundGen[U, V]) value(t *T) V {
+	u := g.g.value(t)
+
+	i := t.s.beginGroup(bindLabel, false) // not standalone, since it depends on u
+	v := g.fn(u).value(t)
+	t.s.endGroup(i, false)
+
+	return v
+}
+
 // Just creates a generator which always produces the given value.
 // Just(val) is a shorthand for [SampledFrom]([]V{val}).
 func Just[V any](val V) *Generator[V] {
@@ -156,12 +262,33 @@ func SampledFrom[S ~[]E, E any](slice S) *Generator[E] {
 	})
 }
 
+// SampledFromWeighted creates a generator which produces values from the given slice,
+// with each value chosen with probability proportional to its weight.
+// Generated values shrink towards the first value of the slice.
+// SampledFromWeighted panics if slice is empty, if lengths of slice and weights differ,
+// or if any of the weights is not positive.
+func SampledFromWeighted[S ~[]E, E any](slice S, weights []int) *Generator[E] {
+	assertf(len(slice) > 0, "slice should not be empty")
+	assertf(len(slice) == len(weights), "got %v weights for %v values", len(weights), len(slice))
+	for i, w := range weights {
+		assertf(w > 0, "weight %v of value %v should be positive", w, i)
+	}
+
+	return newGenerator[E](&sampledGen[E]{
+		slice: slice,
+		die:   newLoadedDie(weights),
+	})
+}
+
 type sampledGen[E any] struct {
 	slice []E
+	die   *loadedDie
 }
 
 func (g *sampledGen[E]) String() string {
-	if len(g.slice) == 1 {
+	if g.die != nil {
+		return fmt.Sprintf("SampledFromWeighted(%v %T)", len(g.slice), g.slice[0])
+	} else if len(g.slice) == 1 {
 		return fmt.Sprintf("Just(%v)", g.slice[0])
 	} else {
 		return fmt.Sprintf("SampledFrom(%v %T)", len(g.slice), g.slice[0])
@@ -169,6 +296,10 @@ func (g *sampledGen[E]) String() string {
 }
 
 func (g *sampledGen[E]) value(t *T) E {
+	if g.die != nil {
+		return g.slice[g.die.roll(t.s)]
+	}
+
 	i := genIndex(t.s, len(g.slice), true)
 
 	return g.slice[i]
@@ -218,20 +349,60 @@ func OneOf[V any](gens ...*Generator[V]) *Generator[V] {
 	})
 }
 
+// Weighted is a generator with its relative weight, for use with [Frequency].
+type Weighted[V any] struct {
+	Weight int
+	Gen    *Generator[V]
+}
+
+// Frequency creates a generator which produces each value by selecting one of generators
+// with probability proportional to its weight, and producing a value from it.
+// Generated values shrink towards the ones produced by the first generator.
+// Frequency panics if pairs is empty, or if any of the weights is not positive.
+func Frequency[V any](pairs ...Weighted[V]) *Generator[V] {
+	assertf(len(pairs) > 0, "at least one generator should be specified")
+
+	gens := make([]*Generator[V], len(pairs))
+	weights := make([]int, len(pairs))
+	for i, p := range pairs {
+		assertf(p.Weight > 0, "weight %v of generator %v should be positive", p.Weight, i)
+		gens[i], weights[i] = p.Gen, p.Weight
+	}
+
+	return newGenerator[V](&oneOfGen[V]{
+		gens:    gens,
+		weights: weights,
+		die:     newLoadedDie(weights),
+	})
+}
+
 type oneOfGen[V any] struct {
-	gens []*Generator[V]
+	gens    []*Generator[V]
+	weights []int
+	die     *loadedDie
 }
 
 func (g *oneOfGen[V]) String() string {
 	strs := make([]string, len(g.gens))
-	for i, g := range g.gens {
-		strs[i] = g.String()
+	for i, gen := range g.gens {
+		if g.die != nil {
+			strs[i] = fmt.Sprintf("%v: %v", g.weights[i], gen)
+		} else {
+			strs[i] = gen.String()
+		}
 	}
 
+	if g.die != nil {
+		return fmt.Sprintf("Frequency(%v)", strings.Join(strs, ", "))
+	}
 	return fmt.Sprintf("OneOf(%v)", strings.Join(strs, ", "))
 }
 
 func (g *oneOfGen[V]) value(t *T) V {
+	if g.die != nil {
+		return g.gens[g.die.roll(t.s)].value(t)
+	}
+
 	i := genIndex(t.s, len(g.gens), true)
 
 	return g.gens[i].value(t)
@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 
 	. "pgregory.net/rapid"
@@ -117,6 +118,41 @@ func TestCustomCleanup(t *testing.T) {
 	})
 }
 
+func TestRecursive(t *testing.T) {
+	t.Parallel()
+
+	g := Recursive(Just("x"), func(child *Generator[string]) *Generator[string] {
+		return Map(SliceOfN(child, 1, 3), func(s []string) string { return "(" + strings.Join(s, " ") + ")" })
+	}, 8)
+
+	nested := 0
+	Check(t, func(t *T) {
+		s := g.Draw(t, "s")
+		depth := 0
+		for _, c := range s {
+			switch c {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+			if depth < 0 {
+				t.Fatalf("unbalanced parentheses in %q", s)
+			}
+			if depth > 1 {
+				nested++
+			}
+		}
+		if depth != 0 {
+			t.Fatalf("unbalanced parentheses in %q", s)
+		}
+	})
+
+	if nested == 0 {
+		t.Fatal("no nested values generated")
+	}
+}
+
 func TestFilter(t *testing.T) {
 	t.Parallel()
 
@@ -144,6 +180,23 @@ func TestMap(t *testing.T) {
 	})
 }
 
+func TestBind(t *testing.T) {
+	t.Parallel()
+
+	g := Bind(IntRange(0, 10), func(n int) *Generator[[][]int] {
+		return SliceOfN(SliceOfN(Int(), n, n), n, n)
+	})
+
+	Check(t, func(t *T) {
+		m := g.Draw(t, "m")
+		for _, row := range m {
+			if len(row) != len(m) {
+				t.Fatalf("got %vx%v matrix", len(m), len(row))
+			}
+		}
+	})
+}
+
 func TestSampledFrom(t *testing.T) {
 	t.Parallel()
 
@@ -162,6 +215,52 @@ func TestSampledFrom(t *testing.T) {
 	}
 }
 
+func TestSampledFromWeighted(t *testing.T) {
+	t.Parallel()
+
+	g := SampledFromWeighted([]string{"common", "rare"}, []int{10, 1})
+
+	rare := 0
+	Check(t, func(t *T) {
+		s := g.Draw(t, "s")
+		switch s {
+		case "common":
+		case "rare":
+			rare++
+		default:
+			t.Fatalf("got impossible %q", s)
+		}
+	})
+
+	if rare == 0 {
+		t.Fatal("rare value never generated")
+	}
+}
+
+func TestFrequency(t *testing.T) {
+	t.Parallel()
+
+	g := Frequency(
+		Weighted[int]{Weight: 100, Gen: IntRange(0, 9)},
+		Weighted[int]{Weight: 10, Gen: IntRange(10, 19)},
+	)
+
+	rare := 0
+	Check(t, func(t *T) {
+		n := g.Draw(t, "n")
+		if n < 0 || n > 19 {
+			t.Fatalf("got impossible %v", n)
+		}
+		if n >= 10 {
+			rare++
+		}
+	})
+
+	if rare == 0 {
+		t.Fatal("rare generator never used")
+	}
+}
+
 func TestOneOf_SameType(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,228 @@
+// Copyright 2019 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rapid
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"slices"
+)
+
+const (
+	complexSpecialProb = 0.1
+	complexEdgeProb    = 0.3
+	complexPureProb    = 0.2
+)
+
+// Zero must be the first one, for special components to shrink towards it.
+var complexSpecials = []float64{0, math.Copysign(0, -1), math.Inf(1), math.Inf(-1), math.NaN()}
+
+// Complex64 creates a generator of 64-bit complex numbers. Real and imaginary parts
+// of generated numbers can be signed zeros, infinities or NaNs, and numbers
+// can be purely real or purely imaginary. Generated numbers shrink towards 0+0i.
+func Complex64() *Generator[complex64] {
+	return newGenerator[complex64](&complex64Gen{
+		complexGen{
+			reMin:    -math.MaxFloat32,
+			reMax:    math.MaxFloat32,
+			imMin:    -math.MaxFloat32,
+			imMax:    math.MaxFloat32,
+			specials: true,
+		},
+	})
+}
+
+// Complex64Range creates a generator of 64-bit complex numbers with real part in range
+// [real(min), real(max)] and imaginary part in range [imag(min), imag(max)].
+// Range boundaries can be infinite. Real and imaginary parts are biased
+// towards 0, ±1 and the finite range boundaries.
+func Complex64Range(min complex64, max complex64) *Generator[complex64] {
+	return newGenerator[complex64](&complex64Gen{
+		newComplexRange(complex128(min), complex128(max)),
+	})
+}
+
+// Complex64Abs creates a generator of 64-bit complex numbers with absolute value
+// (as returned by [cmplx.Abs]) of at most max.
+func Complex64Abs(max float32) *Generator[complex64] {
+	return newGenerator[complex64](&complex64Gen{
+		newComplexAbs(float64(max)),
+	})
+}
+
+// Complex128 creates a generator of 128-bit complex numbers. Real and imaginary parts
+// of generated numbers can be signed zeros, infinities or NaNs, and numbers
+// can be purely real or purely imaginary. Generated numbers shrink towards 0+0i.
+func Complex128() *Generator[complex128] {
+	return newGenerator[complex128](&complex128Gen{
+		complexGen{
+			reMin:    -math.MaxFloat64,
+			reMax:    math.MaxFloat64,
+			imMin:    -math.MaxFloat64,
+			imMax:    math.MaxFloat64,
+			specials: true,
+		},
+	})
+}
+
+// Complex128Range creates a generator of 128-bit complex numbers with real part in range
+// [real(min), real(max)] and imaginary part in range [imag(min), imag(max)].
+// Range boundaries can be infinite. Real and imaginary parts are biased
+// towards 0, ±1 and the finite range boundaries.
+func Complex128Range(min complex128, max complex128) *Generator[complex128] {
+	return newGenerator[complex128](&complex128Gen{
+		newComplexRange(min, max),
+	})
+}
+
+// Complex128Abs creates a generator of 128-bit complex numbers with absolute value
+// (as returned by [cmplx.Abs]) of at most max.
+func Complex128Abs(max float64) *Generator[complex128] {
+	return newGenerator[complex128](&complex128Gen{
+		newComplexAbs(max),
+	})
+}
+
+func newComplexRange(min complex128, max complex128) complexGen {
+	assertf(!cmplx.IsNaN(min), "min should not be a NaN")
+	assertf(!cmplx.IsNaN(max), "max should not be a NaN")
+	assertf(real(min) <= real(max) && imag(min) <= imag(max), "invalid range [%v, %v]", min, max)
+
+	return complexGen{
+		reMin:    real(min),
+		reMax:    real(max),
+		imMin:    imag(min),
+		imMax:    imag(max),
+		hasRange: true,
+	}
+}
+
+func newComplexAbs(max float64) complexGen {
+	assertf(max >= 0 && !math.IsInf(max, 0), "invalid absolute value %v", max)
+
+	return complexGen{
+		reMin:  -max,
+		reMax:  max,
+		imMin:  -max,
+		imMax:  max,
+		abs:    max,
+		hasAbs: true,
+	}
+}
+
+type complexGen struct {
+	reMin    float64
+	reMax    float64
+	imMin    float64
+	imMax    float64
+	abs      float64
+	hasRange bool
+	hasAbs   bool
+	specials bool
+}
+type complex64Gen struct{ complexGen }
+type complex128Gen struct{ complexGen }
+
+func (g *complexGen) stringImpl(kind string) string {
+	if g.hasRange {
+		return fmt.Sprintf("%sRange(%g, %g)", kind, complex(g.reMin, g.imMin), complex(g.reMax, g.imMax))
+	} else if g.hasAbs {
+		return fmt.Sprintf("%sAbs(%g)", kind, g.abs)
+	}
+
+	return fmt.Sprintf("%s()", kind)
+}
+func (g *complex64Gen) String() string {
+	return g.stringImpl("Complex64")
+}
+func (g *complex128Gen) String() string {
+	return g.stringImpl("Complex128")
+}
+
+func (g *complex64Gen) value(t *T) complex64 {
+	re, im := g.valueImpl(t.s, float32SignifBits)
+	return complex(float32(re), float32(im))
+}
+func (g *complex128Gen) value(t *T) complex128 {
+	re, im := g.valueImpl(t.s, float64SignifBits)
+	return complex(re, im)
+}
+
+func (g *complexGen) valueImpl(s bitStream, signifBits uint) (float64, float64) {
+	re := g.component(s, g.reMin, g.reMax, signifBits)
+
+	imMin, imMax := g.imMin, g.imMax
+	if g.hasAbs {
+		// |im| <= sqrt(abs^2 - re^2), computed without overflow
+		a := math.Abs(re)
+		bound := math.Sqrt(g.abs-a) * math.Sqrt(g.abs/2+a/2) * math.Sqrt2
+		imMin, imMax = -math.Min(bound, g.abs), math.Min(bound, g.abs)
+	}
+	im := g.component(s, imMin, imMax, signifBits)
+
+	if flipBiasedCoin(s, complexPureProb) {
+		if flipBiasedCoin(s, 0.5) {
+			if g.reMin <= 0 && g.reMax >= 0 {
+				re = 0
+			}
+		} else if imMin <= 0 && imMax >= 0 {
+			im = 0
+		}
+	}
+
+	if g.hasAbs {
+		// compensate for rounding errors in the bound
+		if signifBits == float32SignifBits {
+			for cmplx.Abs(complex128(complex(float32(re), float32(im)))) > g.abs {
+				im = float64(math.Nextafter32(float32(im), 0))
+			}
+		} else {
+			for cmplx.Abs(complex(re, im)) > g.abs {
+				im = math.Nextafter(im, 0)
+			}
+		}
+	}
+
+	return re, im
+}
+
+func (g *complexGen) component(s bitStream, min float64, max float64, signifBits uint) float64 {
+	var f float64
+	if signifBits == float32SignifBits {
+		f = float64(float32FromParts(genFloatRange(s, min, max, signifBits)))
+	} else {
+		f = float64FromParts(genFloatRange(s, min, max, signifBits))
+	}
+
+	if g.specials && flipBiasedCoin(s, complexSpecialProb) {
+		f = complexSpecials[genIndex(s, len(complexSpecials), true)]
+	} else if !g.specials && flipBiasedCoin(s, complexEdgeProb) {
+		edges := complexEdges(min, max)
+		f = edges[genIndex(s, len(edges), false)]
+	}
+
+	return f
+}
+
+// complexEdges returns the edge cases of range [min, max], smallest by absolute value first,
+// for them to shrink like the values of the range do.
+func complexEdges(min float64, max float64) []float64 {
+	var edges []float64
+	for _, e := range []float64{0, 1, -1, min, max} {
+		if e >= min && e <= max && !math.IsInf(e, 0) && !slices.Contains(edges, e) {
+			edges = append(edges, e)
+		}
+	}
+	if len(edges) == 0 {
+		edges = append(edges, min) // both range boundaries are the same infinity
+	}
+	slices.SortStableFunc(edges, func(a, b float64) int { return cmp.Compare(math.Abs(a), math.Abs(b)) })
+
+	return edges
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rapid_test
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	. "pgregory.net/rapid"
+)
+
+func TestComplexExamples(t *testing.T) {
+	gens := []*Generator[any]{
+		Complex64().AsAny(),
+		Complex64Range(-1-1i, 1+1i).AsAny(),
+		Complex64Abs(10).AsAny(),
+		Complex128().AsAny(),
+		Complex128Range(0, 100+1i).AsAny(),
+		Complex128Abs(1).AsAny(),
+	}
+
+	for _, g := range gens {
+		t.Run(g.String(), func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				t.Log(g.Example())
+			}
+		})
+	}
+}
+
+func TestComplexSpecials(t *testing.T) {
+	t.Parallel()
+
+	g := Complex128()
+	var gotNaN, gotInf, gotNegZero, gotReal, gotImag bool
+	for i := 0; i < 10000; i++ {
+		z := g.Example(i)
+		re, im := real(z), imag(z)
+		gotNaN = gotNaN || cmplx.IsNaN(z)
+		gotInf = gotInf || math.IsInf(re, 0) || math.IsInf(im, 0)
+		gotNegZero = gotNegZero || (re == 0 && math.Signbit(re)) || (im == 0 && math.Signbit(im))
+		gotReal = gotReal || (im == 0 && re != 0 && !math.IsNaN(re))
+		gotImag = gotImag || (re == 0 && im != 0 && !math.IsNaN(im))
+	}
+	if !gotNaN || !gotInf || !gotNegZero || !gotReal || !gotImag {
+		t.Fatalf("special values not generated: NaN %v, infinity %v, negative zero %v, purely real %v, purely imaginary %v", gotNaN, gotInf, gotNegZero, gotReal, gotImag)
+	}
+}
+
+func TestComplexRange(t *testing.T) {
+	t.Parallel()
+
+	Check(t, func(t *T) {
+		re := SliceOfN(Float64(), 2, 2).Draw(t, "re")
+		im := SliceOfN(Float64(), 2, 2).Draw(t, "im")
+		min := complex(math.Min(re[0], re[1]), math.Min(im[0], im[1]))
+		max := complex(math.Max(re[0], re[1]), math.Max(im[0], im[1]))
+
+		z := Complex128Range(min, max).Draw(t, "z")
+		if real(z) < real(min) || real(z) > real(max) || imag(z) < imag(min) || imag(z) > imag(max) {
+			t.Fatalf("got %v outside of [%v, %v]", z, min, max)
+		}
+	})
+}
+
+func TestComplexAbs(t *testing.T) {
+	t.Parallel()
+
+	Check(t, func(t *T) {
+		max := Float64Min(0).Draw(t, "max")
+		z := Complex128Abs(max).Draw(t, "z")
+		if cmplx.Abs(z) > max {
+			t.Fatalf("got %v with absolute value %v greater than %v", z, cmplx.Abs(z), max)
+		}
+
+		max32 := Float32Min(0).Draw(t, "max32")
+		z32 := Complex64Abs(max32).Draw(t, "z32")
+		if cmplx.Abs(complex128(z32)) > float64(max32) {
+			t.Fatalf("got %v with absolute value %v greater than %v", z32, cmplx.Abs(complex128(z32)), max32)
+		}
+	})
+}
+
+func TestMakeComplex(t *testing.T) {
+	t.Parallel()
+
+	type point struct {
+		A complex64
+		B complex128
+	}
+
+	Make[point]().Example()
+}
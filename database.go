@@ -0,0 +1,219 @@
+// Copyright 2020 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rapid
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+const targetsKeySuffix = " targets" // Go test names never contain spaces
+
+var defaultDatabase = DirectoryDatabase(filepath.Join("testdata", "rapid"))
+
+// Example is a test case stored in an [ExampleDatabase].
+type Example struct {
+	// Version is the version of rapid which has generated the example.
+	// Examples from other versions are replayed on a best-effort basis,
+	// because the same Data can produce different values.
+	Version string
+	// Seed is the PRNG seed the example has been generated from, if any.
+	Seed uint64
+	// Data is the bitstream used to generate the example.
+	Data []uint64
+	// Output is the human-readable output of the test case, for reference.
+	Output []byte
+	// Test is the name of the test the example has been generated by.
+	Test string
+	// GoVersion is the version of Go the example has been generated with.
+	GoVersion string
+	// Draws are the values drawn by the test case, in order. When replaying
+	// examples from other versions of rapid, they are used to check
+	// whether the example still produces the same values.
+	Draws []ExampleDraw
+
+	file   string // source file of DirectoryDatabase examples
+	format string // fail file format of DirectoryDatabase examples
+}
+
+// ExampleDraw describes a single value drawn by an [Example].
+type ExampleDraw struct {
+	Label     string // label passed to [Generator.Draw]
+	Generator string // generator description, as returned by [Generator.String]
+	Value     string // value, formatted with %#v
+
+	v any // value itself, for -rapid.emit
+}
+
+// ExampleDatabase stores test cases between test runs. Examples are keyed
+// by test name, with "<test name> targets" key used for the best test cases
+// found by [T.Target].
+//
+// Before generating new test cases, [Check] replays the stored examples.
+// Failing test cases are kept as regression tests after they pass again,
+// unless -rapid.prune is specified; the best test cases for [T.Target]
+// are replaced by the new ones.
+// Minimized failing test cases are saved after the check. Databases should
+// treat examples with the same Data as identical. Fetch can return an error
+// together with the examples, if some of the stored examples can not be loaded.
+type ExampleDatabase interface {
+	Save(key string, ex Example) error
+	Fetch(key string) ([]Example, error)
+	Delete(key string, ex Example) error
+}
+
+// DirectoryDatabase creates an [ExampleDatabase] which stores examples
+// as fail files in dir, with a subdirectory per key. File names are derived
+// from the examples' data, so identical examples are saved only once.
+// The best test cases for [T.Target] are stored with the .targets extension.
+//
+// Default database is DirectoryDatabase("testdata/rapid").
+func DirectoryDatabase(dir string) ExampleDatabase {
+	return &dirDatabase{dir: dir}
+}
+
+type dirDatabase struct {
+	dir string
+}
+
+func (db *dirDatabase) Save(key string, ex Example) error {
+	return saveFailFile(failFileName(db.dir, key, ex.Data), ex)
+}
+
+func (db *dirDatabase) Fetch(key string) ([]Example, error) {
+	matches, err := filepath.Glob(failFilePattern(db.dir, key))
+	if err != nil {
+		return nil, err
+	}
+
+	var examples []Example
+	var errs []error
+	for _, file := range matches {
+		ex, err := loadFailFile(file)
+		if err != nil {
+			// keep the file, it may have been written by a newer version of rapid
+			errs = append(errs, fmt.Errorf("skipping fail file %q: %w", file, err))
+			continue
+		}
+		examples = append(examples, ex)
+	}
+
+	return examples, errors.Join(errs...)
+}
+
+func (db *dirDatabase) Delete(key string, ex Example) error {
+	file := ex.file
+	if file == "" {
+		file = failFileName(db.dir, key, ex.Data)
+	}
+
+	err := os.Remove(file)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete fail file %q: %w", file, err)
+	}
+
+	return nil
+}
+
+// MemoryDatabase creates an [ExampleDatabase] which stores examples in memory.
+// It is mostly useful for testing.
+func MemoryDatabase() ExampleDatabase {
+	return &memDatabase{examples: map[string][]Example{}}
+}
+
+type memDatabase struct {
+	mu       sync.Mutex
+	examples map[string][]Example
+}
+
+func (db *memDatabase) Save(key string, ex Example) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	ex.Data = slices.Clone(ex.Data)
+	ex.Output = slices.Clone(ex.Output)
+	ex.Draws = slices.Clone(ex.Draws)
+	for i, e := range db.examples[key] {
+		if slices.Equal(e.Data, ex.Data) {
+			db.examples[key][i] = ex
+			return nil
+		}
+	}
+	db.examples[key] = append(db.examples[key], ex)
+
+	return nil
+}
+
+func (db *memDatabase) Fetch(key string) ([]Example, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return slices.Clone(db.examples[key]), nil
+}
+
+func (db *memDatabase) Delete(key string, ex Example) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.examples[key] = slices.DeleteFunc(db.examples[key], func(e Example) bool {
+		return slices.Equal(e.Data, ex.Data)
+	})
+
+	return nil
+}
+
+// saveTargets replaces the stored best test cases for [T.Target] with the ones from st.
+func saveTargets(tb tb, db ExampleDatabase, st *checkStats) {
+	key := tb.Name() + targetsKeySuffix
+
+	examples, err := db.Fetch(key)
+	if err != nil {
+		tb.Logf("[rapid] failed to fetch examples: %v", err)
+	}
+	for _, ex := range examples {
+		best := false
+		for _, tgt := range st.targets {
+			best = best || slices.Equal(tgt.buf, ex.Data)
+		}
+		if !best {
+			err := db.Delete(key, ex)
+			if err != nil {
+				tb.Logf("[rapid] %v", err)
+			}
+		}
+	}
+
+	for _, label := range sortedKeys(st.targets) {
+		tgt := st.targets[label]
+		ex := Example{
+			Version: rapidVersion,
+			Data:    tgt.buf,
+			Output:  []byte(fmt.Sprintf("best %q target score %v", label, tgt.score)),
+		}
+		err := db.Save(key, ex)
+		if err != nil {
+			tb.Logf("[rapid] %v", err)
+		}
+	}
+}
+
+// exampleFile returns the name of the file ex is stored in, if any.
+func exampleFile(db ExampleDatabase, key string, ex Example) string {
+	d, ok := db.(*dirDatabase)
+	switch {
+	case !ok:
+		return ""
+	case ex.file != "":
+		return ex.file
+	default:
+		return failFileName(d.dir, key, ex.Data)
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2020 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rapid
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDirectoryDatabase(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	db := DirectoryDatabase(dir)
+	ex := Example{Version: rapidVersion, Seed: 42, Data: []uint64{1, 2, 3}, Output: []byte("output")}
+
+	for i := 0; i < 2; i++ {
+		if err := db.Save("TestFoo/bar", ex); err != nil {
+			t.Fatal(err)
+		}
+	}
+	legacy := filepath.Join(dir, "TestFoo_bar", "TestFoo_bar-20200101000000-1.fail")
+	if err := saveFailFile(legacy, Example{Version: rapidVersion, Data: []uint64{4}}); err != nil {
+		t.Fatal(err)
+	}
+	garbage := filepath.Join(dir, "TestFoo_bar", "TestFoo_bar-garbage.fail")
+	if err := os.WriteFile(garbage, []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	examples, err := db.Fetch("TestFoo/bar")
+	if err == nil || !strings.Contains(err.Error(), garbage) {
+		t.Errorf("unparsable fail file %q has not been reported: %v", garbage, err)
+	}
+	if len(examples) != 2 {
+		t.Fatalf("got %v examples instead of 2: %v", len(examples), examples)
+	}
+	if _, err := os.Stat(garbage); err != nil {
+		t.Errorf("unparsable fail file %q has been deleted: %v", garbage, err)
+	}
+
+	for _, e := range examples {
+		if err := db.Delete("TestFoo/bar", e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	examples, _ = db.Fetch("TestFoo/bar")
+	if len(examples) != 0 {
+		t.Errorf("got %v examples after deleting all of them", len(examples))
+	}
+}
+
+func TestDirectoryDatabase_Targets(t *testing.T) {
+	t.Parallel()
+
+	db := DirectoryDatabase(t.TempDir())
+	keys := []string{"TestFoo" + targetsKeySuffix, "TestFoo", "TestFoo/targets", "TestFoo_targets", "TestFoo#targets"}
+	for i, key := range keys {
+		if err := db.Save(key, Example{Version: rapidVersion, Data: []uint64{uint64(i)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	examples, err := db.Fetch(keys[0])
+	if err != nil || len(examples) != 1 || !slices.Equal(examples[0].Data, []uint64{0}) {
+		t.Fatalf("got %v (%v) instead of the best target only", examples, err)
+	}
+	for _, key := range keys[1:] {
+		examples, _ := db.Fetch(key)
+		for _, ex := range examples {
+			if slices.Equal(ex.Data, []uint64{0}) {
+				t.Errorf("best target fetched for %q", key)
+			}
+		}
+	}
+}
+
+func TestDirectoryDatabase_NewerFormat(t *testing.T) {
+	t.Parallel()
+
+	for _, nofailfile := range []bool{false, true} {
+		dir := t.TempDir()
+		db := DirectoryDatabase(dir)
+		if err := db.Save(t.Name(), Example{Version: rapidVersion, Data: []uint64{1, 2, 3}}); err != nil {
+			t.Fatal(err)
+		}
+		file := failFileName(dir, t.Name(), []uint64{1, 2, 3})
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = bytes.Replace(data, []byte(failfileFormatKey+": "+failfileFormat), []byte(failfileFormatKey+": v3"), 1)
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg := flags
+		cfg.checks = 10
+		cfg.failfile = ""
+		cfg.nofailfile = nofailfile
+		_, _, _, failures := doCheck(t, checkDeadline(nil), &cfg, baseSeed(), db, nil, func(t *T) {
+			_ = Int().Draw(t, "i")
+		})
+		if len(failures) != 0 {
+			t.Fatalf("unexpected failures %v", failures)
+		}
+		if _, err := os.Stat(file); err != nil {
+			t.Fatalf("fail file of a newer format has been deleted (nofailfile %v): %v", nofailfile, err)
+		}
+	}
+}
+
+func TestMemoryDatabase(t *testing.T) {
+	t.Parallel()
+
+	db := MemoryDatabase()
+	data := []uint64{1, 2, 3}
+	_ = db.Save("a", Example{Data: data, Seed: 1})
+	_ = db.Save("a", Example{Data: data, Seed: 2})
+	_ = db.Save("b", Example{Data: []uint64{4}})
+	data[0] = 0
+
+	examples, _ := db.Fetch("a")
+	if len(examples) != 1 || examples[0].Seed != 2 || !slices.Equal(examples[0].Data, []uint64{1, 2, 3}) {
+		t.Fatalf("unexpected examples %v", examples)
+	}
+
+	_ = db.Delete("a", Example{Data: []uint64{1, 2, 3}})
+	if examples, _ := db.Fetch("a"); len(examples) != 0 {
+		t.Errorf("got %v examples after deleting all of them", len(examples))
+	}
+	if examples, _ := db.Fetch("b"); len(examples) != 1 {
+		t.Errorf("got %v examples instead of 1", len(examples))
+	}
+}
+
+func TestDatabase_Failures(t *testing.T) {
+	t.Parallel()
+
+	cfg := flags
+	cfg.checks = 100
+	cfg.failfile = ""
+	cfg.nofailfile = false
+
+	db := MemoryDatabase()
+	fixed := false
+	prop := func(t *T) {
+		if Int().Draw(t, "i") > 1000 && !fixed {
+			t.Fatalf("big")
+		}
+	}
+
+	_, _, _, failures := doCheck(t, checkDeadline(nil), &cfg, baseSeed(), db, nil, prop)
+	if len(failures) != 1 {
+		t.Fatalf("got %v failures instead of 1", len(failures))
+	}
+	reportFailure(ignoreErrorsTB{t}, &cfg, db, prop, 0, failures[0], "")
+
+	examples, _ := db.Fetch(t.Name())
+	if len(examples) != 1 || !slices.Equal(examples[0].Data, failures[0].buf) {
+		t.Fatalf("failure has not been saved: %v", examples)
+	}
+
+	runs := 0
+	_, _, _, failures = doCheck(t, checkDeadline(nil), &cfg, baseSeed(), db, nil, func(t *T) { runs++; prop(t) })
+	if len(failures) != 1 || runs != 2 || !slices.Equal(failures[0].buf, examples[0].Data) {
+		t.Fatalf("stored failure has not been replayed first (%v runs): %v", runs, failures)
+	}
+
+	fixed = true
+	_, _, _, failures = doCheck(t, checkDeadline(nil), &cfg, baseSeed(), db, nil, prop)
+	if len(failures) != 0 {
+		t.Fatalf("unexpected failures %v", failures)
+	}
+	if examples, _ := db.Fetch(t.Name()); len(examples) != 1 {
+		t.Fatalf("passing example has not been kept: %v", examples)
+	}
+
+	cfg.prune = true
+	_, _, _, failures = doCheck(t, checkDeadline(nil), &cfg, baseSeed(), db, nil, prop)
+	if len(failures) != 0 {
+		t.Fatalf("unexpected failures %v", failures)
+	}
+	if examples, _ := db.Fetch(t.Name()); len(examples) != 0 {
+		t.Fatalf("passing example has not been pruned: %v", examples)
+	}
+}
+
+func TestDatabase_Targets(t *testing.T) {
+	t.Parallel()
+
+	cfg := flags
+	db := MemoryDatabase()
+	prop := func(t *T) {
+		t.Target(float64(Int().Draw(t, "i")), "i")
+	}
+
+	s := newRandomBitStream(baseSeed(), true)
+	tt := newT(t, s, false, nil)
+	tt.stats = &caseStats{}
+	if err := checkOnce(tt, prop); err != nil {
+		t.Fatal(err)
+	}
+	st := newCheckStats()
+	st.updateTargets(tt.stats, s.data)
+	saveTargets(t, db, st)
+
+	examples, _ := db.Fetch(t.Name() + targetsKeySuffix)
+	if len(examples) != 1 || !slices.Equal(examples[0].Data, s.data) {
+		t.Fatalf("best target has not been saved: %v", examples)
+	}
+
+	st = newCheckStats()
+	if _, ok := checkDatabase(t, &cfg, db, st, prop); ok {
+		t.Fatalf("unexpected failure")
+	}
+	if tgt := st.targets["i"]; tgt == nil || !slices.Equal(tgt.buf, s.data) {
+		t.Fatalf("stored target has not been loaded: %v", tgt)
+	}
+}
+
+func TestDatabase_OldVersion(t *testing.T) {
+	t.Parallel()
+
+	cfg := flags
+	cfg.nofailfile = false
+	cfg.prune = true
+
+	prop := func(t *T) {
+		_ = IntRange(0, 100).Draw(t, "i")
+	}
+
+	tr := newT(t, newRandomBitStream(baseSeed(), true), false, nil)
+	tr.recordDraws = true
+	_ = checkOnce(tr, prop)
+	data := tr.s.(*randomBitStream).data
+	other := []ExampleDraw{{Label: "i", Generator: "IntRange(0, 100)", Value: "101"}}
+
+	for _, tt := range []struct {
+		name  string
+		draws []ExampleDraw
+		kept  bool
+	}{
+		{"same draws", tr.drawLog, false},
+		{"different draws", other, true},
+	} {
+		db := MemoryDatabase()
+		_ = db.Save(t.Name(), Example{Version: "v0.0.1", Data: data, Draws: tt.draws})
+
+		if _, ok := checkDatabase(t, &cfg, db, nil, prop); ok {
+			t.Fatalf("%v: unexpected failure", tt.name)
+		}
+		if examples, _ := db.Fetch(t.Name()); (len(examples) == 1) != tt.kept {
+			t.Errorf("%v: got %v stored examples after replay", tt.name, len(examples))
+		}
+	}
+}
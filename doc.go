@@ -13,13 +13,15 @@ fails the current test and presents an automatically minimized
 version of the failing test case.
 
 [T.Repeat] is used to construct state machine (sometimes called "stateful"
-or "model-based") tests.
+or "model-based") tests. [RepeatCommands] does the same for commands with
+preconditions, checked against a model of the system. [RepeatParallel]
+checks that concurrent systems are linearizable with respect to a sequential model.
 
 # Generators
 
 Primitives:
   - [Bool]
-  - [Rune], [RuneFrom]
+  - [Rune], [RuneFrom], [RuneFromSet]
   - [Byte], [ByteMin], [ByteMax], [ByteRange]
   - [Int], [IntMin], [IntMax], [IntRange]
   - [Int8], [Int8Min], [Int8Max], [Int8Range]
@@ -34,24 +36,42 @@ Primitives:
   - [Uintptr], [UintptrMin], [UintptrMax], [UintptrRange]
   - [Float32], [Float32Min], [Float32Max], [Float32Range]
   - [Float64], [Float64Min], [Float64Max], [Float64Range]
+  - [Complex64], [Complex64Range], [Complex64Abs]
+  - [Complex128], [Complex128Range], [Complex128Abs]
+
+Arbitrary precision:
+  - [BigInt], [BigIntBits], [BigIntRange]
+  - [BigRat]
+  - [BigFloat]
+
+Network:
+  - [IPv4Addr], [IPv6Addr], [Addr]
+  - [AddrPort]
+  - [Prefix], [PrefixIn]
+
+Time:
+  - [Time], [TimeRange]
+  - [Duration], [DurationRange]
+  - [Location]
 
 Collections:
   - [String], [StringMatching], [StringOf], [StringOfN], [StringN]
   - [SliceOfBytesMatching]
   - [SliceOf], [SliceOfN], [SliceOfDistinct], [SliceOfNDistinct]
-  - [Permutation]
-  - [MapOf], [MapOfN], [MapOfValues], [MapOfNValues]
+  - [Permutation], [SubsetOf], [SubsetOfN]
+  - [MapOf], [MapOfN], [MapOfValues], [MapOfNValues], [SubmapOf]
 
 User-defined types:
   - [Custom]
   - [Make]
 
 Other:
-  - [Map],
+  - [Map], [Bind]
   - [Generator.Filter]
-  - [SampledFrom], [Just]
-  - [OneOf]
-  - [Deferred]
+  - [SampledFrom], [SampledFromWeighted], [Just]
+  - [OneOf], [Frequency]
+  - [Zip2], [Zip3]
+  - [Deferred], [Recursive]
   - [Ptr]
 */
 package rapid
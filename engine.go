@@ -14,7 +14,6 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
 	"regexp"
 	"runtime"
 	"strconv"
@@ -33,6 +32,11 @@ const (
 	maxTestTimeout  = 24 * time.Hour
 	shrinkStepBound = 10 * time.Second // can be improved by taking average checkOnce runtime into account
 
+	maxDistinctErrors = 8
+
+	coverageChecksMult = 100
+	coverageZ          = 4.4 // ~1e-5 probability of a wrong coverage verdict
+
 	tracebackLen  = 32
 	tracebackStop = "pgregory.net/rapid.checkOnce"
 	runtimePrefix = "runtime."
@@ -50,14 +54,22 @@ var (
 type cmdline struct {
 	checks     int
 	steps      int
+	maxSteps   int
 	failfile   string
 	nofailfile bool
+	prune      bool
 	seed       uint64
 	log        bool
 	verbose    bool
 	debug      bool
 	debugvis   bool
 	shrinkTime time.Duration
+	noshrink   bool
+	reproduce  int
+	multibug   bool
+	stats      bool
+	coverage   bool
+	emit       string
 }
 
 func init() {
@@ -66,12 +78,109 @@ func init() {
 	flag.IntVar(&flags.steps, "rapid.steps", defaults.steps, "rapid: average number of Repeat actions to execute")
 	flag.StringVar(&flags.failfile, "rapid.failfile", defaults.failfile, "rapid: fail file to use to reproduce test failure")
 	flag.BoolVar(&flags.nofailfile, "rapid.nofailfile", defaults.nofailfile, "rapid: do not write fail files on test failures")
+	flag.BoolVar(&flags.prune, "rapid.prune", defaults.prune, "rapid: delete stored failing test cases which no longer fail")
 	flag.Uint64Var(&flags.seed, "rapid.seed", defaults.seed, "rapid: PRNG seed to start with (0 to use a random one)")
 	flag.BoolVar(&flags.log, "rapid.log", defaults.log, "rapid: eager verbose output to stdout (to aid with unrecoverable test failures)")
 	flag.BoolVar(&flags.verbose, "rapid.v", defaults.verbose, "rapid: verbose output")
 	flag.BoolVar(&flags.debug, "rapid.debug", defaults.debug, "rapid: debugging output")
 	flag.BoolVar(&flags.debugvis, "rapid.debugvis", defaults.debugvis, "rapid: debugging visualization")
 	flag.DurationVar(&flags.shrinkTime, "rapid.shrinktime", defaults.shrinkTime, "rapid: maximum time to spend on test case minimization")
+	flag.BoolVar(&flags.noshrink, "rapid.noshrink", defaults.noshrink, "rapid: report the first failing test case without minimizing it")
+	flag.IntVar(&flags.reproduce, "rapid.reproduce", defaults.reproduce, "rapid: number of attempts to reproduce a failure with -rapid.noshrink (to detect flaky tests)")
+	flag.BoolVar(&flags.multibug, "rapid.multibug", defaults.multibug, "rapid: continue checking after the first failure and report all distinct failures")
+	flag.BoolVar(&flags.stats, "rapid.stats", defaults.stats, "rapid: report statistics collected with T.Event and T.Label")
+	flag.BoolVar(&flags.coverage, "rapid.checkcoverage", defaults.coverage, "rapid: keep checking until T.Cover requirements are met or failed with statistical confidence")
+	flag.StringVar(&flags.emit, "rapid.emit", defaults.emit, "rapid: file to append Go regression tests replaying the failures of top-level property functions to")
+}
+
+// Settings customizes a single [CheckWith] or [MakeCheckWith] call.
+// Zero fields leave the corresponding defaults unchanged. Command-line flags
+// (and matching environment variables), when specified explicitly,
+// take precedence over Settings.
+type Settings struct {
+	// Checks is the number of checks to perform (-rapid.checks).
+	Checks int
+	// Steps is the average number of [T.Repeat] actions to execute (-rapid.steps).
+	// It is not a limit: use MaxSteps to cap the number of actions.
+	Steps int
+	// MaxSteps, if positive, is the maximum number of [T.Repeat] actions to execute,
+	// which takes precedence over Steps and the minimum number of steps of [T.RepeatN].
+	MaxSteps int
+	// ShrinkTime is the maximum time to spend on test case minimization (-rapid.shrinktime).
+	ShrinkTime time.Duration
+	// Seed is the PRNG seed to start with (-rapid.seed).
+	Seed uint64
+	// NoShrink disables minimization of failing test cases (-rapid.noshrink).
+	// The first failure found is reported as is, which is useful for
+	// non-deterministic or expensive properties.
+	NoShrink bool
+	// Reproduce is the number of attempts to reproduce a failure when NoShrink
+	// is set (-rapid.reproduce). A failure which does not reproduce on every
+	// attempt is reported as flaky.
+	Reproduce int
+	// MultipleBugs makes rapid continue checking after the first failure,
+	// and minimize and report every distinct failure found (-rapid.multibug).
+	// Failures are considered distinct when their tracebacks differ.
+	MultipleBugs bool
+	// CheckCoverage makes rapid decide whether [T.Cover] requirements are met
+	// with statistical confidence, running more test cases if necessary
+	// (-rapid.checkcoverage).
+	CheckCoverage bool
+	// Database is the database to store and replay test cases with
+	// (by default, DirectoryDatabase("testdata/rapid")).
+	Database ExampleDatabase
+	// OnStats, when not nil, is called with the statistics collected
+	// using [T.Event] and [T.Label] after all test cases have been run.
+	OnStats func(Stats)
+}
+
+func (s Settings) cmdline() cmdline {
+	cfg := flags
+
+	if s.Checks != 0 && !flagSet("rapid.checks", "RAPID_CHECKS") {
+		cfg.checks = s.Checks
+	}
+	if s.Steps != 0 && !flagSet("rapid.steps", "RAPID_STEPS") {
+		cfg.steps = s.Steps
+	}
+	if s.MaxSteps > 0 {
+		cfg.maxSteps = s.MaxSteps
+	}
+	if s.ShrinkTime != 0 && !flagSet("rapid.shrinktime", "RAPID_SHRINKTIME") {
+		cfg.shrinkTime = s.ShrinkTime
+	}
+	if s.Seed != 0 && !flagSet("rapid.seed", "RAPID_SEED") {
+		cfg.seed = s.Seed
+	}
+	if s.NoShrink && !flagSet("rapid.noshrink", "RAPID_NOSHRINK") {
+		cfg.noshrink = true
+	}
+	if s.Reproduce != 0 && !flagSet("rapid.reproduce", "RAPID_REPRODUCE") {
+		cfg.reproduce = s.Reproduce
+	}
+	if s.MultipleBugs && !flagSet("rapid.multibug", "RAPID_MULTIBUG") {
+		cfg.multibug = true
+	}
+	if s.CheckCoverage && !flagSet("rapid.checkcoverage", "RAPID_CHECKCOVERAGE") {
+		cfg.coverage = true
+	}
+
+	return cfg
+}
+
+func flagSet(name string, env string) bool {
+	if _, ok := os.LookupEnv(env); ok {
+		return true
+	}
+
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+
+	return set
 }
 
 func defaultCmdline() cmdline {
@@ -89,12 +198,19 @@ func loadCmdlineDefaults(lookup func(string) (string, bool)) cmdline {
 	defaults.steps = envInt(lookup, "RAPID_STEPS", defaults.steps)
 	defaults.failfile = envString(lookup, "RAPID_FAILFILE", defaults.failfile)
 	defaults.nofailfile = envBool(lookup, "RAPID_NOFAILFILE", defaults.nofailfile)
+	defaults.prune = envBool(lookup, "RAPID_PRUNE", defaults.prune)
 	defaults.seed = envUint64(lookup, "RAPID_SEED", defaults.seed)
 	defaults.log = envBool(lookup, "RAPID_LOG", defaults.log)
 	defaults.verbose = envBool(lookup, "RAPID_V", defaults.verbose)
 	defaults.debug = envBool(lookup, "RAPID_DEBUG", defaults.debug)
 	defaults.debugvis = envBool(lookup, "RAPID_DEBUGVIS", defaults.debugvis)
 	defaults.shrinkTime = envDuration(lookup, "RAPID_SHRINKTIME", defaults.shrinkTime)
+	defaults.noshrink = envBool(lookup, "RAPID_NOSHRINK", defaults.noshrink)
+	defaults.reproduce = envInt(lookup, "RAPID_REPRODUCE", defaults.reproduce)
+	defaults.multibug = envBool(lookup, "RAPID_MULTIBUG", defaults.multibug)
+	defaults.stats = envBool(lookup, "RAPID_STATS", defaults.stats)
+	defaults.coverage = envBool(lookup, "RAPID_CHECKCOVERAGE", defaults.coverage)
+	defaults.emit = envString(lookup, "RAPID_EMIT", defaults.emit)
 
 	return defaults
 }
@@ -188,8 +304,8 @@ func checkDeadline(tb tb) time.Time {
 	return d
 }
 
-func shrinkDeadline(deadline time.Time) time.Time {
-	d := time.Now().Add(flags.shrinkTime)
+func shrinkDeadline(deadline time.Time, shrinkTime time.Duration) time.Time {
+	d := time.Now().Add(shrinkTime)
 	max := deadline.Add(-shrinkStepBound) // account for the fact that shrink deadline is checked before the step
 	if d.After(max) {
 		d = max
@@ -203,7 +319,14 @@ func shrinkDeadline(deadline time.Time) time.Time {
 // [*T.Fatalf], [*T.Fatal], [*T.Errorf], [*T.Error], [*T.FailNow] or [*T.Fail].
 func Check(t TB, prop func(*T)) {
 	t.Helper()
-	checkTB(t, checkDeadline(t), prop)
+	checkTB(t, checkDeadline(t), Settings{}, prop)
+}
+
+// CheckWith is like [Check], but uses settings to override the defaults
+// specified by -rapid.* command-line flags for this call only.
+func CheckWith(t TB, settings Settings, prop func(*T)) {
+	t.Helper()
+	checkTB(t, checkDeadline(t), settings, prop)
 }
 
 // MakeCheck is a convenience function for defining subtests suitable for
@@ -223,7 +346,15 @@ func Check(t TB, prop func(*T)) {
 func MakeCheck(prop func(*T)) func(*testing.T) {
 	return func(t *testing.T) {
 		t.Helper()
-		checkTB(t, checkDeadline(t), prop)
+		checkTB(t, checkDeadline(t), Settings{}, prop)
+	}
+}
+
+// MakeCheckWith is like [MakeCheck], but uses settings like [CheckWith] does.
+func MakeCheckWith(settings Settings, prop func(*T)) func(*testing.T) {
+	return func(t *testing.T) {
+		t.Helper()
+		checkTB(t, checkDeadline(t), settings, prop)
 	}
 }
 
@@ -267,163 +398,401 @@ func checkFuzz(tb tb, prop func(*T), input []byte) {
 	}
 }
 
-func checkTB(tb tb, deadline time.Time, prop func(*T)) {
+func checkTB(tb tb, deadline time.Time, settings Settings, prop func(*T)) {
 	tb.Helper()
 
-	checks := flags.checks
+	cfg := settings.cmdline()
 	if testing.Short() {
-		checks /= 5
+		cfg.checks /= 5
+	}
+	seed := cfg.seed
+	if seed == 0 {
+		seed = baseSeed()
+	}
+
+	db := settings.Database
+	if db == nil {
+		db = defaultDatabase
 	}
 
 	start := time.Now()
-	valid, invalid, earlyExit, seed, failfile, buf, err1, err2 := doCheck(tb, deadline, checks, baseSeed(), flags.failfile, true, prop)
+	st := newCheckStats()
+	valid, invalid, earlyExit, failures := doCheck(tb, deadline, &cfg, seed, db, st, prop)
 	dt := time.Since(start)
 
-	if err1 == nil && err2 == nil {
-		if valid == checks || (earlyExit && valid > 0) {
-			tb.Logf("[rapid] OK, passed %v tests (%v)", valid, dt)
-		} else {
+	if (cfg.stats || cfg.verbose) && !st.empty() {
+		tb.Log(st)
+	}
+	if settings.OnStats != nil {
+		settings.OnStats(st.export())
+	}
+
+	if len(failures) == 0 {
+		insufficient := st.insufficientCoverage(cfg.coverage)
+		switch {
+		case valid < cfg.checks && !(earlyExit && valid > 0):
 			tb.Errorf("[rapid] only generated %v valid tests from %v total (%v)", valid, valid+invalid, dt)
+		case len(insufficient) > 0:
+			tb.Errorf("[rapid] insufficient coverage after %v tests (%v):\n%v", valid, dt, strings.Join(insufficient, "\n"))
+		default:
+			tb.Logf("[rapid] OK, passed %v tests (%v)", valid, dt)
+			for _, label := range sortedKeys(st.targets) {
+				tgt := st.targets[label]
+				tb.Logf("[rapid] best %q target score %v, achieved with:", label, tgt.score)
+				t := newT(tb, newBufBitStream(tgt.buf, false), true, nil)
+				t.cfg = &cfg
+				_ = checkOnce(t, prop)
+			}
+			if len(st.targets) > 0 && !cfg.nofailfile {
+				saveTargets(tb, db, st)
+			}
 		}
 	} else {
-		if failfile == "" && !flags.nofailfile {
-			_, failfile = failFileName(tb.Name())
-			out := captureTestOutput(tb, prop, buf)
-			err := saveFailFile(failfile, rapidVersion, out, seed, buf)
-			if err != nil {
-				tb.Logf("[rapid] %v", err)
-				failfile = ""
+		for i, f := range failures {
+			var note string
+			if cfg.noshrink {
+				note = " (not minimized)"
+			}
+			if len(failures) > 1 {
+				note += fmt.Sprintf(" (distinct failure %v of %v)", i+1, len(failures))
 			}
-		}
 
-		var repr string
-		switch {
-		case failfile != "" && seed != 0:
-			repr = fmt.Sprintf("-rapid.failfile=%q (or -rapid.seed=%d)", failfile, seed)
-		case failfile != "":
-			repr = fmt.Sprintf("-rapid.failfile=%q", failfile)
-		case seed != 0:
-			repr = fmt.Sprintf("-rapid.seed=%d", seed)
+			reportFailure(tb, &cfg, db, prop, valid, f, note)
 		}
+	}
 
-		name := regexp.QuoteMeta(tb.Name())
-		if traceback(err1) == traceback(err2) {
-			if err2.isStopTest() {
-				tb.Errorf("[rapid] failed after %v tests: %v\nTo reproduce, specify -run=%q %v\nFailed test output:", valid, err2, name, repr)
-			} else {
-				tb.Errorf("[rapid] panic after %v tests: %v\nTo reproduce, specify -run=%q %v\nTraceback:\n%vFailed test output:", valid, err2, name, repr, traceback(err2))
-			}
+	if tb.Failed() {
+		tb.FailNow() // do not try to run any checks after the first failed one
+	}
+}
+
+func reportFailure(tb tb, cfg *cmdline, db ExampleDatabase, prop func(*T), valid int, f failure, note string) {
+	tb.Helper()
+
+	failfile := f.failfile
+	if failfile == "" && !cfg.nofailfile {
+		ex := Example{
+			Version:   rapidVersion,
+			Seed:      f.seed,
+			Data:      f.buf,
+			Test:      tb.Name(),
+			GoVersion: runtime.Version(),
+		}
+		ex.Output, ex.Draws = captureTestOutput(tb, cfg, prop, f.buf)
+		err := db.Save(tb.Name(), ex)
+		if err != nil {
+			tb.Logf("[rapid] %v", err)
 		} else {
-			tb.Errorf("[rapid] flaky test, can not reproduce a failure\nTo try to reproduce, specify -run=%q %v\nTraceback (%v):\n%vOriginal traceback (%v):\n%vFailed test output:", name, repr, err2, traceback(err2), err1, traceback(err1))
+			failfile = exampleFile(db, tb.Name(), ex)
 		}
+	}
 
-		_ = checkOnce(newT(tb, newBufBitStream(buf, false), true, nil), prop) // output using (*testing.T).Log for proper line numbers
+	var repr string
+	switch {
+	case failfile != "" && f.seed != 0:
+		repr = fmt.Sprintf("-rapid.failfile=%q (or -rapid.seed=%d)", failfile, f.seed)
+	case failfile != "":
+		repr = fmt.Sprintf("-rapid.failfile=%q", failfile)
+	case f.seed != 0:
+		repr = fmt.Sprintf("-rapid.seed=%d", f.seed)
 	}
 
-	if tb.Failed() {
-		tb.FailNow() // do not try to run any checks after the first failed one
+	name := regexp.QuoteMeta(tb.Name())
+	if traceback(f.err1) == traceback(f.err2) {
+		if f.err2.isStopTest() {
+			tb.Errorf("[rapid] failed after %v tests%v: %v\nTo reproduce, specify -run=%q %v\nFailed test output:", valid, note, f.err2, name, repr)
+		} else {
+			tb.Errorf("[rapid] panic after %v tests%v: %v\nTo reproduce, specify -run=%q %v\nTraceback:\n%vFailed test output:", valid, note, f.err2, name, repr, traceback(f.err2))
+		}
+	} else {
+		tb.Errorf("[rapid] flaky test, can not reproduce a failure\nTo try to reproduce, specify -run=%q %v\nTraceback (%v):\n%vOriginal traceback (%v):\n%vFailed test output:", name, repr, f.err2, traceback(f.err2), f.err1, traceback(f.err1))
+	}
+
+	t := newT(tb, newBufBitStream(f.buf, false), true, nil)
+	t.cfg = cfg
+	_ = checkOnce(t, prop) // output using (*testing.T).Log for proper line numbers
+
+	if cfg.emit != "" {
+		_, draws := captureTestOutput(tb, cfg, prop, f.buf)
+		err := emitRegressionTest(cfg.emit, tb.Name(), prop, f.err2.Error(), draws, failfile)
+		if err != nil {
+			tb.Logf("[rapid] %v", err)
+		} else {
+			tb.Logf("[rapid] regression test has been written to %q", cfg.emit)
+		}
 	}
 }
 
-func doCheck(tb tb, deadline time.Time, checks int, seed uint64, failfile string, globFailFiles bool, prop func(*T)) (int, int, bool, uint64, string, []uint64, *testError, *testError) {
+// failure is a failing test case found by doCheck. err1 is the original error,
+// and err2 is the error produced when re-running the test case using buf.
+type failure struct {
+	seed     uint64
+	failfile string
+	buf      []uint64
+	err1     *testError
+	err2     *testError
+}
+
+func doCheck(tb tb, deadline time.Time, cfg *cmdline, seed uint64, db ExampleDatabase, st *checkStats, prop func(*T)) (int, int, bool, []failure) {
 	tb.Helper()
 
 	assertf(!tb.Failed(), "check function called with *testing.T which has already failed")
 
-	var failfiles []string
-	if failfile != "" {
-		failfiles = []string{failfile}
-	}
-	if globFailFiles {
-		matches, _ := filepath.Glob(failFilePattern(tb.Name()))
-		failfiles = append(failfiles, matches...)
+	if cfg.failfile != "" {
+		ex, err := loadFailFile(cfg.failfile)
+		if err != nil {
+			tb.Logf("[rapid] ignoring fail file: %v", err)
+		} else if err1, err2, _ := checkExample(tb, cfg, fmt.Sprintf("fail file %q", cfg.failfile), ex, nil, prop); err1 != nil && !err1.isInvalidData() {
+			return 0, 0, false, []failure{{failfile: cfg.failfile, buf: ex.Data, err1: err1, err2: err2}}
+		}
 	}
-	for _, failfile := range failfiles {
-		buf, err1, err2 := checkFailFile(tb, failfile, prop)
-		if err1 != nil || err2 != nil {
-			return 0, 0, false, 0, failfile, buf, err1, err2
+	if db != nil {
+		if f, ok := checkDatabase(tb, cfg, db, st, prop); ok {
+			return 0, 0, false, []failure{f}
 		}
 	}
 
-	valid, invalid, earlyExit, seed, err1 := findBug(tb, deadline, checks, seed, prop)
-	if err1 == nil {
-		return valid, invalid, earlyExit, 0, "", nil, nil, nil
+	valid, invalid, earlyExit, found := findBug(tb, deadline, cfg, seed, st, prop)
+	if len(found) == 0 {
+		return valid, invalid, earlyExit, nil
 	}
 
-	s := newRandomBitStream(seed, true)
-	t := newT(tb, s, flags.verbose, nil)
-	t.Logf("[rapid] trying to reproduce the failure")
-	err2 := checkOnce(t, prop)
-	if !sameError(err1, err2) {
-		return valid, invalid, false, seed, "", s.data, err1, err2
+	var failures []failure
+	if cfg.noshrink {
+		for _, f := range found {
+			f.err2 = reproduceFailure(tb, cfg, f.buf, f.err1, prop)
+			failures = append(failures, f)
+		}
+		return valid, invalid, false, failures
 	}
 
-	t.Logf("[rapid] trying to minimize the failing test case")
-	buf, err3 := shrink(tb, shrinkDeadline(deadline), s.recordedBits, err2, prop)
+	seen := map[string]struct{}{}
+	for _, f := range found {
+		seen[traceback(f.err1)] = struct{}{}
+	}
+
+	deadline = shrinkDeadline(deadline, cfg.shrinkTime)
+	for _, f := range found {
+		var rec *recordedBits
+		t := newT(tb, nil, flags.verbose, nil)
+		if f.seed != 0 {
+			s := newRandomBitStream(f.seed, true)
+			t.s, rec = s, &s.recordedBits
+		} else {
+			s := newBufBitStream(f.buf, true)
+			t.s, rec = s, &s.recordedBits
+		}
+		t.cfg = cfg
+		t.Logf("[rapid] trying to reproduce the failure")
+		err2 := checkOnce(t, prop)
+		if !sameError(f.err1, err2) {
+			failures = append(failures, failure{seed: f.seed, buf: rec.data, err1: f.err1, err2: err2})
+			continue
+		}
+
+		t.Logf("[rapid] trying to minimize the failing test case")
+		shrunk := shrink(tb, deadline, cfg, *rec, err2, prop, seen)
+		shrunk[0].seed = f.seed
+		failures = append(failures, shrunk...)
+	}
 
-	return valid, invalid, false, seed, "", buf, err2, err3
+	return valid, invalid, false, failures
 }
 
-func checkFailFile(tb tb, failfile string, prop func(*T)) ([]uint64, *testError, *testError) {
+// checkDatabase replays the examples stored in db, deleting the ones which no longer fail.
+// The best test cases for [T.Target] are kept as long as they are valid, and become the
+// starting points for targeting.
+func checkDatabase(tb tb, cfg *cmdline, db ExampleDatabase, st *checkStats, prop func(*T)) (failure, bool) {
 	tb.Helper()
 
-	version, _, buf, err := loadFailFile(failfile)
-	if err != nil {
-		tb.Logf("[rapid] ignoring fail file: %v", err)
-		return nil, nil, nil
-	}
-	if version != rapidVersion {
-		tb.Logf("[rapid] ignoring fail file: version %q differs from rapid version %q", version, rapidVersion)
-		return nil, nil, nil
+	for _, key := range []string{tb.Name(), tb.Name() + targetsKeySuffix} {
+		examples, err := db.Fetch(key)
+		if err != nil {
+			tb.Logf("[rapid] failed to fetch examples: %v", err) // the ones which have been loaded are still checked
+		}
+
+		for i, ex := range examples {
+			file := exampleFile(db, key, ex)
+			name := fmt.Sprintf("fail file %q", file)
+			if file == "" {
+				name = fmt.Sprintf("stored example #%v of %q", i, key)
+			}
+
+			var c *caseStats
+			if key != tb.Name() && st != nil {
+				c = &caseStats{}
+			}
+			err1, err2, reliable := checkExample(tb, cfg, name, ex, c, prop)
+			switch {
+			case err1 != nil && !err1.isInvalidData():
+				return failure{failfile: file, buf: ex.Data, err1: err1, err2: err2}, true
+			case err1 == nil && c != nil && c.hasTargets():
+				st.updateTargets(c, ex.Data)
+			case !reliable:
+				// keep the example, it may still be useful as a regression test
+			case key == tb.Name() && !cfg.prune:
+				// keep the failing test case which has been fixed, as a regression test
+			case !cfg.nofailfile:
+				err := db.Delete(key, ex)
+				if err != nil {
+					tb.Logf("[rapid] %v", err)
+				}
+			}
+		}
 	}
 
-	s1 := newBufBitStream(buf, false)
+	return failure{}, false
+}
+
+// checkExample replays ex, returning the error of the first run, and of the second one
+// if the first run has failed. Examples from other versions of rapid are replayed on
+// a best-effort basis: the values drawn are compared with the recorded ones, and the
+// result is reliable only if they match.
+func checkExample(tb tb, cfg *cmdline, name string, ex Example, c *caseStats, prop func(*T)) (*testError, *testError, bool) {
+	tb.Helper()
+
+	s1 := newBufBitStream(ex.Data, false)
 	t1 := newT(tb, s1, flags.verbose, nil)
+	t1.cfg = cfg
+	t1.stats = c
+	t1.recordDraws = ex.Version != rapidVersion
 	err1 := checkOnce(t1, prop)
+
+	reliable := true
+	if ex.Version != rapidVersion {
+		mismatch := drawsMismatch(ex, t1.drawLog)
+		reliable = mismatch == ""
+		if reliable {
+			tb.Logf("[rapid] %v is from rapid %v, replayed drawing the same values", name, ex.Version)
+		} else {
+			tb.Logf("[rapid] %v is from rapid %v, replayed on a best-effort basis: %v", name, ex.Version, mismatch)
+		}
+	}
+
 	if err1 == nil {
-		return nil, nil, nil
+		return nil, nil, reliable
 	}
 	if err1.isInvalidData() {
-		tb.Logf("[rapid] fail file %q is no longer valid", failfile)
-		return nil, nil, nil
+		tb.Logf("[rapid] %v is no longer valid", name)
+		return err1, nil, reliable
 	}
 
-	s2 := newBufBitStream(buf, false)
+	s2 := newBufBitStream(ex.Data, false)
 	t2 := newT(tb, s2, flags.verbose, nil)
+	t2.cfg = cfg
 	t2.Logf("[rapid] trying to reproduce the failure")
 	err2 := checkOnce(t2, prop)
 
-	return buf, err1, err2
+	return err1, err2, reliable
+}
+
+// drawsMismatch describes how the values drawn differ from the ones recorded in ex.
+func drawsMismatch(ex Example, drawn []ExampleDraw) string {
+	if ex.format == failfileFormatV1 {
+		return "values drawn are not recorded in the old format"
+	}
+
+	for i, want := range ex.Draws {
+		if i >= len(drawn) {
+			return fmt.Sprintf("only %v of %v values have been drawn", len(drawn), len(ex.Draws))
+		}
+		if got := drawn[i]; got.Label != want.Label || got.Value != want.Value {
+			return fmt.Sprintf("draw %v differs: %v = %v instead of %v = %v", i, got.Label, got.Value, want.Label, want.Value)
+		}
+	}
+	if len(drawn) > len(ex.Draws) {
+		return fmt.Sprintf("%v values have been drawn instead of %v", len(drawn), len(ex.Draws))
+	}
+
+	return ""
+}
+
+func reproduceFailure(tb tb, cfg *cmdline, buf []uint64, err *testError, prop func(*T)) *testError {
+	tb.Helper()
+
+	for i := 0; i < cfg.reproduce; i++ {
+		t := newT(tb, newBufBitStream(buf, false), flags.verbose, nil)
+		t.cfg = cfg
+		t.Logf("[rapid] trying to reproduce the failure (attempt %v of %v)", i+1, cfg.reproduce)
+		err2 := checkOnce(t, prop)
+		if !sameError(err, err2) {
+			return err2
+		}
+	}
+
+	return err
 }
 
-func findBug(tb tb, deadline time.Time, checks int, seed uint64, prop func(*T)) (int, int, bool, uint64, *testError) {
+func findBug(tb tb, deadline time.Time, cfg *cmdline, seed uint64, st *checkStats, prop func(*T)) (int, int, bool, []failure) {
 	tb.Helper()
 
 	var (
-		r       = newRandomBitStream(0, false)
-		t       = newT(tb, r, flags.verbose, nil)
-		valid   = 0
-		invalid = 0
+		r        = newRandomBitStream(0, cfg.noshrink) // without shrinking, failing bitstream must be recorded right away
+		ctx      jsf64ctx
+		t        = newT(tb, r, flags.verbose, nil)
+		valid    = 0
+		invalid  = 0
+		failed   = 0
+		failures []failure
+		seen     = map[string]struct{}{}
 	)
+	t.cfg = cfg
+
+	more := func() bool {
+		n := valid + failed
+		if n < cfg.checks || (st != nil && len(st.targets) > 0 && n < cfg.checks*2) {
+			return true
+		}
+		return cfg.coverage && st != nil && n < cfg.checks*coverageChecksMult && st.coverageUndecided()
+	}
 
 	var total time.Duration
-	for valid < checks && invalid < checks*invalidChecksMult {
-		iter := valid + invalid
+	for more() && invalid < cfg.checks*invalidChecksMult {
+		iter := valid + invalid + failed
 		if iter > 0 && time.Until(deadline) < total/time.Duration(iter)*5 {
 			if t.shouldLog() {
 				t.Logf("[rapid] early exit after test #%v (%v)", iter, total)
 			}
-			return valid, invalid, true, 0, nil
+			return valid, invalid, true, failures
 		}
 
 		seed += uint64(iter)
-		r.init(seed)
+		n := valid + failed
+		targeting := st != nil && len(st.targets) > 0 && n >= cfg.checks && n < cfg.checks*2
+		var rec *recordedBits
+		if targeting {
+			ctx.init(seed)
+			s := newBufBitStream(st.mutateTarget(&ctx, iter), true)
+			t.s, rec = s, &s.recordedBits
+		} else {
+			r.init(seed)
+			if r.persist {
+				r.recordedBits = recordedBits{persist: true}
+			}
+			t.s, rec = r, &r.recordedBits
+		}
 		start := time.Now()
 		if t.shouldLog() {
-			t.Logf("[rapid] test #%v start (seed %v)", iter+1, seed)
+			if targeting {
+				t.Logf("[rapid] test #%v start (mutating best target, seed %v)", iter+1, seed)
+			} else {
+				t.Logf("[rapid] test #%v start (seed %v)", iter+1, seed)
+			}
 		}
 
+		t.failed = "" // t is reused between test cases
+		if st != nil {
+			t.stats = &caseStats{}
+		}
 		err := checkOnce(t, prop)
+		if err == nil && st != nil && !rec.persist && t.stats.hasTargets() {
+			r.persist = true // re-run the test case recording its data, to be able to mutate the best ones
+			r.init(seed)
+			r.recordedBits = recordedBits{persist: true}
+			t.failed = ""
+			t.stats = &caseStats{}
+			err = checkOnce(t, prop)
+		}
 		dt := time.Since(start)
 		total += dt
 		if err == nil {
@@ -431,6 +800,12 @@ func findBug(tb tb, deadline time.Time, checks int, seed uint64, prop func(*T))
 				t.Logf("[rapid] test #%v OK (%v)", iter+1, dt)
 			}
 			valid++
+			if st != nil {
+				if !targeting {
+					st.add(t.stats)
+				}
+				st.updateTargets(t.stats, rec.data)
+			}
 		} else if err.isInvalidData() {
 			if t.shouldLog() {
 				t.Logf("[rapid] test #%v invalid (%v)", iter+1, dt)
@@ -440,11 +815,23 @@ func findBug(tb tb, deadline time.Time, checks int, seed uint64, prop func(*T))
 			if t.shouldLog() {
 				t.Logf("[rapid] test #%v failed: %v", iter+1, err)
 			}
-			return valid, invalid, false, seed, err
+			f := failure{seed: seed, buf: rec.data, err1: err}
+			if targeting {
+				f.seed = 0 // mutated test case can only be reproduced from buf
+			}
+			if !cfg.multibug {
+				return valid, invalid, false, []failure{f}
+			}
+
+			failed++
+			if _, ok := seen[traceback(err)]; !ok && len(failures) < maxDistinctErrors {
+				seen[traceback(err)] = struct{}{}
+				failures = append(failures, f)
+			}
 		}
 	}
 
-	return valid, invalid, false, 0, nil
+	return valid, invalid, false, failures
 }
 
 func checkOnce(t *T, prop func(*T)) (err *testError) {
@@ -460,11 +847,14 @@ func checkOnce(t *T, prop func(*T)) (err *testError) {
 	return nil
 }
 
-func captureTestOutput(tb tb, prop func(*T), buf []uint64) []byte {
+func captureTestOutput(tb tb, cfg *cmdline, prop func(*T), buf []uint64) ([]byte, []ExampleDraw) {
 	var b bytes.Buffer
 	l := log.New(&b, fmt.Sprintf("[%v] ", tb.Name()), log.Lmsgprefix|log.Ldate|log.Ltime|log.Lmicroseconds)
-	_ = checkOnce(newT(tb, newBufBitStream(buf, false), false, l), prop)
-	return b.Bytes()
+	t := newT(tb, newBufBitStream(buf, false), false, l)
+	t.cfg = cfg
+	t.recordDraws = true
+	_ = checkOnce(t, prop)
+	return b.Bytes(), t.drawLog
 }
 
 type invalidData string
@@ -600,13 +990,21 @@ type T struct {
 	cleanups  []func()
 	cleaning  atomic.Bool
 
-	tbLog    bool
-	rawLog   *log.Logger
-	s        bitStream
-	draws    int
-	refDraws []any
-	mu       sync.RWMutex
-	failed   stopTest
+	tbLog       bool
+	rawLog      *log.Logger
+	recordDraws bool
+	drawLog     []ExampleDraw
+	replay      bool
+	pinned      []any
+	cfg         *cmdline
+	stats       *caseStats
+	leaves      map[any]int    // remaining leaves of active Recursive generators
+	history     *actionHistory // actions of the innermost state machine, when logging
+	s           bitStream
+	draws       int
+	refDraws    []any
+	mu          sync.RWMutex
+	failed      stopTest
 }
 
 func newT(tb tb, s bitStream, tbLog bool, rawLog *log.Logger, refDraws ...any) *T {
@@ -618,6 +1016,7 @@ func newT(tb tb, s bitStream, tbLog bool, rawLog *log.Logger, refDraws ...any) *
 		tb:       tb,
 		tbLog:    tbLog,
 		rawLog:   rawLog,
+		cfg:      &flags,
 		s:        s,
 		refDraws: refDraws,
 	}
@@ -868,6 +1267,10 @@ func (t *T) skip(msg string) {
 }
 
 func (t *T) fail(now bool, msg string) {
+	if t.tbLog {
+		t.tb.Helper()
+	}
+
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -878,6 +1281,10 @@ func (t *T) fail(now bool, msg string) {
 }
 
 func (t *T) failOnError() {
+	if t.tbLog {
+		t.tb.Helper()
+	}
+
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
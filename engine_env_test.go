@@ -18,16 +18,23 @@ func TestLoadCmdlineDefaultsUsesBaseValues(t *testing.T) {
 
 func TestLoadCmdlineDefaultsFromEnv(t *testing.T) {
 	env := map[string]string{
-		"RAPID_CHECKS":     "0xc8",
-		"RAPID_STEPS":      "40_000",
-		"RAPID_FAILFILE":   "/tmp/failfile",
-		"RAPID_NOFAILFILE": "true",
-		"RAPID_SEED":       "0x1234",
-		"RAPID_LOG":        "true",
-		"RAPID_V":          "true",
-		"RAPID_DEBUG":      "true",
-		"RAPID_DEBUGVIS":   "true",
-		"RAPID_SHRINKTIME": "45s",
+		"RAPID_CHECKS":        "0xc8",
+		"RAPID_STEPS":         "40_000",
+		"RAPID_FAILFILE":      "/tmp/failfile",
+		"RAPID_NOFAILFILE":    "true",
+		"RAPID_PRUNE":         "true",
+		"RAPID_SEED":          "0x1234",
+		"RAPID_LOG":           "true",
+		"RAPID_V":             "true",
+		"RAPID_DEBUG":         "true",
+		"RAPID_DEBUGVIS":      "true",
+		"RAPID_SHRINKTIME":    "45s",
+		"RAPID_NOSHRINK":      "true",
+		"RAPID_REPRODUCE":     "3",
+		"RAPID_MULTIBUG":      "true",
+		"RAPID_STATS":         "true",
+		"RAPID_CHECKCOVERAGE": "true",
+		"RAPID_EMIT":          "/tmp/regression_test.go",
 	}
 
 	got := loadCmdlineDefaults(func(key string) (string, bool) {
@@ -47,6 +54,9 @@ func TestLoadCmdlineDefaultsFromEnv(t *testing.T) {
 	if !got.nofailfile {
 		t.Fatalf("nofailfile: expected true")
 	}
+	if !got.prune {
+		t.Fatalf("prune: expected true")
+	}
 	if got.seed != 0x1234 {
 		t.Fatalf("seed: got %d, want %d", got.seed, 0x1234)
 	}
@@ -56,6 +66,24 @@ func TestLoadCmdlineDefaultsFromEnv(t *testing.T) {
 	if got.shrinkTime != 45*time.Second {
 		t.Fatalf("shrinkTime: got %v, want %v", got.shrinkTime, 45*time.Second)
 	}
+	if !got.noshrink {
+		t.Fatalf("noshrink: expected true")
+	}
+	if got.reproduce != 3 {
+		t.Fatalf("reproduce: got %d, want %d", got.reproduce, 3)
+	}
+	if !got.multibug {
+		t.Fatalf("multibug: expected true")
+	}
+	if !got.stats {
+		t.Fatalf("stats: expected true")
+	}
+	if !got.coverage {
+		t.Fatalf("coverage: expected true")
+	}
+	if got.emit != "/tmp/regression_test.go" {
+		t.Fatalf("emit: got %q, want %q", got.emit, "/tmp/regression_test.go")
+	}
 }
 
 func TestLoadCmdlineDefaultsInvalidEnvPanics(t *testing.T) {
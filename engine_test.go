@@ -9,9 +9,11 @@ package rapid
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func brokenGen(*T) int { panic("this generator is not working") }
@@ -93,7 +95,7 @@ func BenchmarkCheckOverhead(b *testing.B) {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		checkTB(b, deadline, f)
+		checkTB(b, deadline, Settings{}, f)
 	}
 }
 
@@ -259,3 +261,132 @@ func (ignoreErrorsTB) Errorf(string, ...interface{}) {}
 func (ignoreErrorsTB) Fatal(...interface{})          {}
 func (ignoreErrorsTB) Fatalf(string, ...interface{}) {}
 func (ignoreErrorsTB) Fail()                         {}
+
+func TestCheckWithSettings(t *testing.T) {
+	t.Parallel()
+
+	checks := 7
+	if flagSet("rapid.checks", "RAPID_CHECKS") {
+		checks = flags.checks
+	}
+	if testing.Short() {
+		checks /= 5
+	}
+
+	n := 0
+	CheckWith(t, Settings{Checks: 7}, func(t *T) {
+		_ = Int().Draw(t, "i")
+		n++
+	})
+
+	if n != checks {
+		t.Fatalf("got %v checks instead of %v", n, checks)
+	}
+}
+
+func TestSettingsCmdline(t *testing.T) {
+	t.Parallel()
+
+	cfg := Settings{Steps: 3, ShrinkTime: time.Second}.cmdline()
+	if !flagSet("rapid.steps", "RAPID_STEPS") && cfg.steps != 3 {
+		t.Errorf("got steps %v instead of 3", cfg.steps)
+	}
+	if !flagSet("rapid.shrinktime", "RAPID_SHRINKTIME") && cfg.shrinkTime != time.Second {
+		t.Errorf("got shrink time %v instead of %v", cfg.shrinkTime, time.Second)
+	}
+	if cfg.checks != flags.checks {
+		t.Errorf("got checks %v instead of default %v", cfg.checks, flags.checks)
+	}
+}
+
+func TestNoShrink(t *testing.T) {
+	t.Parallel()
+
+	cfg := flags
+	cfg.checks = 100
+	cfg.failfile = ""
+	cfg.noshrink = true
+	cfg.reproduce = 0
+
+	runs := 0
+	prop := func(t *T) {
+		runs++
+		if Int().Draw(t, "i") != 0 && runs >= 3 {
+			t.Fatalf("failed on run %v", runs)
+		}
+	}
+
+	_, _, _, failures := doCheck(t, checkDeadline(nil), &cfg, baseSeed(), nil, nil, prop)
+	if len(failures) != 1 || !sameError(failures[0].err1, failures[0].err2) {
+		t.Fatalf("unexpected failures: %v", failures)
+	}
+	buf, err1 := failures[0].buf, failures[0].err1
+	if err1.Error() != fmt.Sprintf("failed on run %v", runs) {
+		t.Fatalf("failing test case was re-run: %v runs, %v", runs, err1)
+	}
+
+	err := checkOnce(newT(t, newBufBitStream(buf, false), false, nil), prop)
+	if traceback(err) != traceback(err1) {
+		t.Fatalf("recorded bitstream does not reproduce the failure: %v vs %v", err, err1)
+	}
+}
+
+func TestNoShrinkFlaky(t *testing.T) {
+	t.Parallel()
+
+	cfg := flags
+	cfg.checks = 100
+	cfg.failfile = ""
+	cfg.noshrink = true
+	cfg.reproduce = 2
+
+	failed := false
+	prop := func(t *T) {
+		_ = Int().Draw(t, "i")
+		if !failed {
+			failed = true
+			t.Fatalf("failing only once")
+		}
+	}
+
+	_, _, _, failures := doCheck(t, checkDeadline(nil), &cfg, baseSeed(), nil, nil, prop)
+	if len(failures) != 1 || failures[0].err1 == nil || failures[0].err2 != nil {
+		t.Fatalf("flaky failure not detected: %v", failures)
+	}
+}
+
+func TestMultipleBugs(t *testing.T) {
+	t.Parallel()
+
+	cfg := flags
+	cfg.checks = 100
+	cfg.failfile = ""
+	cfg.multibug = true
+
+	prop := func(t *T) {
+		k := IntRange(0, 1).Draw(t, "k")
+		n := IntRange(0, 1000000).Draw(t, "n")
+		if k == 0 && n > 100 {
+			t.Fatalf("first bug")
+		}
+		if k == 1 && n > 100 {
+			t.Fatalf("second bug")
+		}
+	}
+
+	_, _, _, failures := doCheck(t, checkDeadline(nil), &cfg, baseSeed(), nil, nil, prop)
+	if len(failures) != 2 {
+		t.Fatalf("got %v failures instead of 2", len(failures))
+	}
+
+	msgs := map[string]bool{}
+	for _, f := range failures {
+		if !sameError(f.err1, f.err2) {
+			t.Fatalf("failure is flaky: %v vs %v", f.err1, f.err2)
+		}
+		msgs[f.err2.Error()] = true
+	}
+	if !msgs["first bug"] || !msgs["second bug"] {
+		t.Fatalf("unexpected failures: %v", msgs)
+	}
+}
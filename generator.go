@@ -44,7 +44,12 @@ func (g *Generator[V]) Draw(t *T, label string) V {
 		t.tb.Helper()
 	}
 
-	v := g.value(t)
+	var v V
+	if t.replay {
+		v = pinnedValue(t, g)
+	} else {
+		v = g.value(t)
+	}
 
 	if len(t.refDraws) > 0 {
 		ref := t.refDraws[t.draws]
@@ -53,15 +58,19 @@ func (g *Generator[V]) Draw(t *T, label string) V {
 		}
 	}
 
-	if t.tbLog || t.rawLog != nil {
+	if t.tbLog || t.rawLog != nil || t.recordDraws {
 		if label == "" {
 			label = fmt.Sprintf("#%v", t.draws)
 		}
 
+		if t.recordDraws {
+			t.drawLog = append(t.drawLog, ExampleDraw{Label: label, Generator: g.String(), Value: fmt.Sprintf("%#v", v), v: v})
+		}
 		if t.tbLog {
 			t.tb.Helper()
 		}
 		t.Logf("[rapid] draw %v: %#v", label, v)
+		t.history.draw(label, v)
 	}
 
 	t.draws++
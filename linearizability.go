@@ -0,0 +1,257 @@
+// Copyright 2019 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rapid
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	parallelLabel     = "parallel"
+	parallelMaxSteps  = 8 // linearizability checking is exponential in the number of concurrent operations
+	parallelYieldProb = 0.25
+	parallelRuns      = 10
+)
+
+// ParallelOp is a single operation of a concurrent system under test of type S,
+// with a sequential model of type M, see [RepeatParallel].
+type ParallelOp[S any, M any] struct {
+	// Run executes the operation on the system under test, possibly concurrently
+	// with other operations, and returns its observable result.
+	Run func(S) any
+	// Model applies the operation to the state of the sequential model, returning the new state
+	// and the expected result. Model can be called several times with the same state,
+	// and should not modify it.
+	Model func(M) (M, any)
+}
+
+// RepeatParallel executes a random sequential prefix of operations, followed by random
+// sequences of operations executed concurrently, one sequence per each of threads goroutines.
+// Operations are produced by actions, which should draw all the inputs of the operations
+// they return, and not use t in the returned functions. Operations are executed
+// on a system under test created by setup.
+//
+// RepeatParallel records the history of calls and returns of all operations, and fails
+// the test if the history is not linearizable: that is, if there is no sequential order
+// of operations which respects the history, and in which the result of every operation
+// matches the result of its Model, starting from the init state. Results are compared
+// using [reflect.DeepEqual]. Because concurrency bugs are hard to reproduce, the operations
+// are executed several times, each time on a new system under test.
+func RepeatParallel[S any, M any](t *T, setup func() S, init M, actions map[string]func(*T) ParallelOp[S, M], threads int) {
+	t.Helper()
+	assertf(threads > 0, "number of threads should be positive")
+
+	actionKeys := make([]string, 0, len(actions))
+	for key := range actions {
+		actionKeys = append(actionKeys, key)
+	}
+	if len(actionKeys) == 0 {
+		return
+	}
+	sort.Strings(actionKeys)
+
+	pm := parallelMachine[S, M]{
+		actionKeys: SampledFrom(actionKeys),
+		actions:    actions,
+	}
+
+	prefix := pm.drawCalls(t)
+	seqs := make([][]*parallelCall[S, M], threads)
+	for i := range seqs {
+		seqs[i] = pm.drawCalls(t)
+	}
+
+	calls := append([]*parallelCall[S, M]{}, prefix...)
+	for _, seq := range seqs {
+		calls = append(calls, seq...)
+	}
+	for i := 0; i < parallelRuns; i++ {
+		pm.execute(setup(), prefix, seqs)
+		if !linearizable(init, calls) {
+			t.Fatalf("history is not linearizable:\n%v", formatHistory(prefix, seqs))
+		}
+	}
+}
+
+type parallelMachine[S any, M any] struct {
+	actionKeys *Generator[string]
+	actions    map[string]func(*T) ParallelOp[S, M]
+	clock      atomic.Int64
+}
+
+type parallelCall[S any, M any] struct {
+	name   string
+	op     ParallelOp[S, M]
+	yield  bool
+	call   int64
+	ret    int64
+	result any
+}
+
+func (pm *parallelMachine[S, M]) drawCalls(t *T) []*parallelCall[S, M] {
+	t.Helper()
+
+	var calls []*parallelCall[S, M]
+	repeat := newRepeat(-1, parallelMaxSteps, -1, parallelLabel)
+	for pm.more(t, repeat) {
+		i := t.s.beginGroup(actionLabel, false)
+		name := pm.actionKeys.Draw(t, "action")
+		op := pm.actions[name](t)
+		yield := biasedBool(parallelYieldProb).Draw(t, "yield") // perturb the schedule a bit
+		// pinned values do not use the bitstream
+		t.s.endGroup(i, t.replay)
+
+		calls = append(calls, &parallelCall[S, M]{
+			name:  name,
+			op:    op,
+			yield: yield,
+		})
+	}
+
+	return calls
+}
+
+// more decides whether to draw one more call. Like all the other decisions, it is a labelled draw,
+// for the failures to be reproducible with [Replay].
+func (pm *parallelMachine[S, M]) more(t *T, repeat *repeat) bool {
+	t.Helper()
+
+	if t.replay {
+		return Bool().Draw(t, "more")
+	}
+	return repeat.moreWith(t.s, func(p float64) bool { return biasedBool(p).Draw(t, "more") })
+}
+
+func (pm *parallelMachine[S, M]) execute(sys S, prefix []*parallelCall[S, M], seqs [][]*parallelCall[S, M]) {
+	pm.clock.Store(0)
+	for _, c := range prefix {
+		pm.run(sys, c)
+	}
+
+	panics := make([]any, len(seqs))
+	var wg sync.WaitGroup
+	for i, seq := range seqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { panics[i] = recover() }()
+			for _, c := range seq {
+				pm.run(sys, c)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, r := range panics {
+		if r != nil {
+			panic(r)
+		}
+	}
+}
+
+func (pm *parallelMachine[S, M]) run(sys S, c *parallelCall[S, M]) {
+	if c.yield {
+		runtime.Gosched()
+	}
+
+	c.call = pm.clock.Add(1)
+	c.result = c.op.Run(sys)
+	c.ret = pm.clock.Add(1)
+}
+
+// linearizable checks the history using the algorithm of Wing and Gong, with the memoization
+// of already explored states proposed by Lowe: it tries to linearize (in any valid order)
+// the calls which have not returned before any other pending call has been made.
+func linearizable[S any, M any](init M, calls []*parallelCall[S, M]) bool {
+	done := make([]byte, len(calls))
+	explored := map[string][]M{}
+
+	var search func(state M, left int) bool
+	search = func(state M, left int) bool {
+		if left == 0 {
+			return true
+		}
+
+		key := string(done)
+		for _, s := range explored[key] {
+			if reflect.DeepEqual(s, state) {
+				return false
+			}
+		}
+
+		minRet := int64(math.MaxInt64)
+		for i, c := range calls {
+			if done[i] == 0 && c.ret < minRet {
+				minRet = c.ret
+			}
+		}
+
+		for i, c := range calls {
+			if done[i] != 0 || c.call > minRet {
+				continue
+			}
+			next, result := c.op.Model(state)
+			if !reflect.DeepEqual(result, c.result) {
+				continue
+			}
+			done[i] = 1
+			ok := search(next, left-1)
+			done[i] = 0
+			if ok {
+				return true
+			}
+		}
+
+		explored[key] = append(explored[key], state)
+		return false
+	}
+
+	return search(init, len(calls))
+}
+
+func formatHistory[S any, M any](prefix []*parallelCall[S, M], seqs [][]*parallelCall[S, M]) string {
+	var b strings.Builder
+	format := func(title string, calls []*parallelCall[S, M]) {
+		if len(calls) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "%v:\n", title)
+		for _, c := range calls {
+			fmt.Fprintf(&b, "    [%v, %v] %v -> %#v\n", c.call, c.ret, c.name, c.result)
+		}
+	}
+
+	format("sequential", prefix)
+	for i, seq := range seqs {
+		format(fmt.Sprintf("thread %v", i+1), seq)
+	}
+
+	return b.String()
+}
+
+type biasedBoolGen struct {
+	p float64
+}
+
+func biasedBool(p float64) *Generator[bool] {
+	return newGenerator[bool](&biasedBoolGen{p: p})
+}
+
+func (g *biasedBoolGen) String() string {
+	return fmt.Sprintf("biasedBool(%v)", g.p)
+}
+
+func (g *biasedBoolGen) value(t *T) bool {
+	return flipBiasedCoin(t.s, g.p)
+}
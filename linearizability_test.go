@@ -0,0 +1,210 @@
+// Copyright 2019 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rapid
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type counter interface {
+	Inc() int64
+	Get() int64
+}
+
+type atomicCounter struct {
+	n atomic.Int64
+}
+
+func (c *atomicCounter) Inc() int64 {
+	return c.n.Add(1)
+}
+
+func (c *atomicCounter) Get() int64 {
+	return c.n.Load()
+}
+
+type racyCounter struct {
+	atomicCounter
+}
+
+func (c *racyCounter) Inc() int64 {
+	n := c.n.Load()
+	runtime.Gosched()
+	c.n.Store(n + 1)
+	return n + 1
+}
+
+var counterActions = map[string]func(*T) ParallelOp[counter, int64]{
+	"Inc": func(*T) ParallelOp[counter, int64] {
+		return ParallelOp[counter, int64]{
+			Run:   func(c counter) any { return c.Inc() },
+			Model: func(n int64) (int64, any) { return n + 1, n + 1 },
+		}
+	},
+	"Get": func(*T) ParallelOp[counter, int64] {
+		return ParallelOp[counter, int64]{
+			Run:   func(c counter) any { return c.Get() },
+			Model: func(n int64) (int64, any) { return n, n },
+		}
+	},
+}
+
+func TestRepeatParallel_Atomic(t *testing.T) {
+	t.Parallel()
+
+	Check(t, func(t *T) {
+		RepeatParallel(t, func() counter { return &atomicCounter{} }, 0, counterActions, 3)
+	})
+}
+
+type lockedMap struct {
+	mu sync.Mutex
+	m  map[int]int
+}
+
+func TestRepeatParallel_Mutex(t *testing.T) {
+	t.Parallel()
+
+	Check(t, func(t *T) {
+		RepeatParallel(t, func() *lockedMap { return &lockedMap{m: map[int]int{}} }, map[int]int{}, map[string]func(*T) ParallelOp[*lockedMap, map[int]int]{
+			"Put": func(t *T) ParallelOp[*lockedMap, map[int]int] {
+				k, v := IntRange(0, 3).Draw(t, "k"), Int().Draw(t, "v")
+				return ParallelOp[*lockedMap, map[int]int]{
+					Run: func(m *lockedMap) any {
+						m.mu.Lock()
+						defer m.mu.Unlock()
+						m.m[k] = v
+						return nil
+					},
+					Model: func(s map[int]int) (map[int]int, any) {
+						s2 := make(map[int]int, len(s)+1)
+						for k, v := range s {
+							s2[k] = v
+						}
+						s2[k] = v
+						return s2, nil
+					},
+				}
+			},
+			"Get": func(t *T) ParallelOp[*lockedMap, map[int]int] {
+				k := IntRange(0, 3).Draw(t, "k")
+				return ParallelOp[*lockedMap, map[int]int]{
+					Run: func(m *lockedMap) any {
+						m.mu.Lock()
+						defer m.mu.Unlock()
+						return m.m[k]
+					},
+					Model: func(s map[int]int) (map[int]int, any) {
+						return s, s[k]
+					},
+				}
+			},
+		}, 2)
+	})
+}
+
+func TestRepeatParallel_Racy(t *testing.T) {
+	t.Parallel()
+
+	cfg := flags
+	cfg.checks = 1000
+	cfg.failfile = ""
+	_, _, _, failures := doCheck(t, checkDeadline(nil), &cfg, baseSeed(), nil, nil, func(t *T) {
+		RepeatParallel(t, func() counter { return &racyCounter{} }, 0, counterActions, 2)
+	})
+	if len(failures) == 0 {
+		t.Fatal("racy counter is linearizable")
+	}
+	if msg := failures[0].err1.Error(); !strings.Contains(msg, "not linearizable") {
+		t.Fatalf("unexpected error: %v", msg)
+	}
+}
+
+func TestRepeatParallel_Replay(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	actions := map[string]func(*T) ParallelOp[counter, int64]{}
+	for name, action := range counterActions {
+		actions[name] = func(t *T) ParallelOp[counter, int64] {
+			calls = append(calls, fmt.Sprintf("%v(%v)", name, IntRange(0, 9).Draw(t, "n")))
+			return action(t)
+		}
+	}
+	prop := func(t *T) {
+		RepeatParallel(t, func() counter { return &atomicCounter{} }, 0, actions, 2)
+		calls = append(calls, "|")
+		RepeatParallel(t, func() counter { return &atomicCounter{} }, 0, actions, 3)
+	}
+
+	tr := newT(t, newRandomBitStream(baseSeed(), false), false, nil)
+	tr.recordDraws = true
+	if err := checkOnce(tr, prop); err != nil {
+		t.Fatal(err)
+	}
+	drawn := calls
+
+	var draws []any
+	for _, d := range tr.drawLog {
+		draws = append(draws, d.v)
+	}
+	calls = nil
+	Replay(t, prop, draws...)
+	if got, want := strings.Join(calls, " "), strings.Join(drawn, " "); got != want {
+		t.Fatalf("replayed calls %q instead of %q", got, want)
+	}
+}
+
+func TestLinearizable(t *testing.T) {
+	t.Parallel()
+
+	write := func(v int) ParallelOp[any, int] {
+		return ParallelOp[any, int]{Model: func(int) (int, any) { return v, nil }}
+	}
+	read := ParallelOp[any, int]{Model: func(s int) (int, any) { return s, s }}
+
+	testData := []struct {
+		name  string
+		calls []*parallelCall[any, int]
+		ok    bool
+	}{
+		{"sequential", []*parallelCall[any, int]{
+			{op: write(1), call: 1, ret: 2},
+			{op: read, call: 3, ret: 4, result: 1},
+		}, true},
+		{"stale read", []*parallelCall[any, int]{
+			{op: write(1), call: 1, ret: 2},
+			{op: read, call: 3, ret: 4, result: 0},
+		}, false},
+		{"overlapping old read", []*parallelCall[any, int]{
+			{op: write(1), call: 1, ret: 4},
+			{op: read, call: 2, ret: 3, result: 0},
+		}, true},
+		{"overlapping new read", []*parallelCall[any, int]{
+			{op: write(1), call: 1, ret: 4},
+			{op: read, call: 2, ret: 3, result: 1},
+		}, true},
+		{"read goes back", []*parallelCall[any, int]{
+			{op: write(1), call: 1, ret: 6},
+			{op: read, call: 2, ret: 3, result: 1},
+			{op: read, call: 4, ret: 5, result: 0},
+		}, false},
+	}
+
+	for _, td := range testData {
+		t.Run(td.name, func(t *testing.T) {
+			if ok := linearizable(0, td.calls); ok != td.ok {
+				t.Fatalf("got %v instead of %v", ok, td.ok)
+			}
+		})
+	}
+}
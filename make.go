@@ -8,7 +8,29 @@ package rapid
 
 import (
 	"fmt"
+	"math/big"
+	"net"
+	"net/netip"
 	"reflect"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	locationType = reflect.TypeOf((*time.Location)(nil))
+	bigIntType   = reflect.TypeOf((*big.Int)(nil))
+	bigRatType   = reflect.TypeOf((*big.Rat)(nil))
+	bigFloatType = reflect.TypeOf((*big.Float)(nil))
+	addrType     = reflect.TypeOf(netip.Addr{})
+	addrPortType = reflect.TypeOf(netip.AddrPort{})
+	prefixType   = reflect.TypeOf(netip.Prefix{})
+	ipType       = reflect.TypeOf(net.IP{})
+)
+
+const (
+	makeBigFloatPrec = 53 // same as float64
+	makeMaxLeaves    = 32
 )
 
 // MakeConfig customizes reflection-based generators produced by MakeCustom.
@@ -22,19 +44,25 @@ type MakeConfig struct {
 	// Fields, if specified, provides Generators for fields on a given type that
 	// override the automatic reflection-based generation.
 	Fields map[reflect.Type]map[string]*Generator[any]
+
+	recursion map[reflect.Type]*makeRecursion // types being constructed
+}
+
+// makeRecursion is a reference to the type being constructed from inside of it.
+type makeRecursion struct {
+	used  bool
+	child *Generator[any]
 }
 
 // Make creates a generator of values of type V, using reflection to infer the required structure.
-// Currently, Make may be unable to terminate generation of values of some recursive types, thus using
-// Make with recursive types requires extra care.
+// Recursive types are generated using [Recursive], with zero values as leaves.
 func Make[V any]() *Generator[V] {
 	return MakeCustom[V](MakeConfig{})
 }
 
 // MakeCustom creates a generator of values of type V, using reflection and
 // overrides from MakeConfig to infer the required structure.
-// Currently, Make may be unable to terminate generation of values of some recursive types, thus using
-// Make with recursive types requires extra care.
+// Recursive types are generated using [Recursive], with zero values as leaves.
 func MakeCustom[V any](cfg MakeConfig) *Generator[V] {
 	var zero V
 	gen := cfg.newMakeGen(reflect.TypeOf(zero))
@@ -57,6 +85,15 @@ func (g *makeGen[V]) value(t *T) V {
 }
 
 func (c *MakeConfig) newMakeGen(typ reflect.Type) *Generator[any] {
+	switch typ.Kind() {
+	case reflect.Array, reflect.Map, reflect.Pointer, reflect.Slice, reflect.Struct:
+		return c.newMakeRecursiveGen(typ)
+	}
+
+	return c.newMakeCastGen(typ)
+}
+
+func (c *MakeConfig) newMakeCastGen(typ reflect.Type) *Generator[any] {
 	gen, mayNeedCast := c.newMakeKindGen(typ)
 	if !mayNeedCast || typ.String() == typ.Kind().String() {
 		return gen // fast path with less reflect
@@ -64,6 +101,32 @@ func (c *MakeConfig) newMakeGen(typ reflect.Type) *Generator[any] {
 	return newGenerator[any](&castGen{gen, typ})
 }
 
+// newMakeRecursiveGen detects the types which (indirectly) contain themselves,
+// and generates them using Recursive.
+func (c *MakeConfig) newMakeRecursiveGen(typ reflect.Type) *Generator[any] {
+	if rec, ok := c.recursion[typ]; ok {
+		rec.used = true
+		return Deferred(func() *Generator[any] { return rec.child })
+	}
+
+	if c.recursion == nil {
+		c.recursion = map[reflect.Type]*makeRecursion{}
+	}
+	rec := &makeRecursion{}
+	c.recursion[typ] = rec
+	gen := c.newMakeCastGen(typ)
+	delete(c.recursion, typ)
+
+	if !rec.used {
+		return gen
+	}
+
+	return Recursive(Just(reflect.Zero(typ).Interface()), func(child *Generator[any]) *Generator[any] {
+		rec.child = child
+		return gen
+	}, makeMaxLeaves)
+}
+
 type castGen struct {
 	gen *Generator[any]
 	typ reflect.Type
@@ -94,6 +157,29 @@ func (c *MakeConfig) newMakeKindGen(typ reflect.Type) (gen *Generator[any], mayN
 		}
 	}
 
+	switch typ {
+	case timeType:
+		return Time().AsAny(), false
+	case durationType:
+		return Duration().AsAny(), false
+	case locationType:
+		return Location().AsAny(), false
+	case bigIntType:
+		return BigInt().AsAny(), false
+	case bigRatType:
+		return BigRat().AsAny(), false
+	case bigFloatType:
+		return BigFloat(makeBigFloatPrec).AsAny(), false
+	case addrType:
+		return Addr().AsAny(), false
+	case addrPortType:
+		return AddrPort().AsAny(), false
+	case prefixType:
+		return Prefix().AsAny(), false
+	case ipType:
+		return Map(Addr(), func(a netip.Addr) net.IP { return a.AsSlice() }).AsAny(), false
+	}
+
 	switch typ.Kind() {
 	case reflect.Bool:
 		return Bool().AsAny(), true
@@ -123,12 +209,16 @@ func (c *MakeConfig) newMakeKindGen(typ reflect.Type) (gen *Generator[any], mayN
 		return Float32().AsAny(), true
 	case reflect.Float64:
 		return Float64().AsAny(), true
+	case reflect.Complex64:
+		return Complex64().AsAny(), true
+	case reflect.Complex128:
+		return Complex128().AsAny(), true
 	case reflect.Array:
 		return c.genAnyArray(typ), false
 	case reflect.Map:
 		return c.genAnyMap(typ), false
 	case reflect.Pointer:
-		return Deferred(func() *Generator[any] { return c.genAnyPointer(typ) }), false
+		return c.genAnyPointer(typ), false
 	case reflect.Slice:
 		return c.genAnySlice(typ), false
 	case reflect.String:
@@ -143,10 +233,12 @@ func (c *MakeConfig) newMakeKindGen(typ reflect.Type) (gen *Generator[any], mayN
 func (c *MakeConfig) genAnyPointer(typ reflect.Type) *Generator[any] {
 	elem := typ.Elem()
 	elemGen := c.newMakeGen(elem)
+	rec := c.recursion[typ]
+	recursive := rec != nil && rec.used // nil pointers are leaves of Recursive
 	const pNonNil = 0.5
 
 	return Custom(func(t *T) any {
-		if flipBiasedCoin(t.s, pNonNil) {
+		if recursive || flipBiasedCoin(t.s, pNonNil) {
 			val := elemGen.value(t)
 			ptr := reflect.New(elem)
 			ptr.Elem().Set(reflect.ValueOf(val))
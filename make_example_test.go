@@ -47,9 +47,9 @@ func ExampleMake_tree() {
 		fmt.Println(gen.Example(i))
 	}
 	// Output:
-	// (nil 1 (nil 184 nil))
-	// (((nil -1 (((((nil -485 ((nil -2 ((((nil -5 nil) -9898554875447 nil) -34709387 ((nil 50440 nil) 113 (((((nil -442 nil) -66090341586 nil) 179745 nil) 494 (((nil -2 nil) 543360606020 nil) 15261837 nil)) -1778 nil))) -21034573818 nil)) -5 nil)) 15606609 nil) 882666 (nil 3 nil)) -12 (nil -2 ((nil 1 nil) -2 (((nil 11 nil) -187307 ((nil -198 (nil -6895 nil)) 12027 (nil -539313 nil))) 1532 (nil 6 nil))))) 1745354 nil)) -2 nil) -3 nil)
-	// nil
-	// (((nil -15 (nil 6598 nil)) -131 (nil 317121006373596 ((nil 14 ((nil -9223372036854775808 nil) 1 nil)) 14668 nil))) 590 nil)
+	// ((nil -1902 (((nil 5871 (nil 10 (nil -9223372036854775808 (((((((nil -8 (nil -24 ((nil 561860 (nil 12315 nil)) 3 (nil 87 (nil -3 nil))))) -1758 nil) -43403209096129 (nil -88 (nil -130450326583 nil))) 1345 nil) 19942 nil) -9223372036854775808 nil) -8 (nil -52431 nil))))) -261 (nil 141 nil)) 236258 nil)) 1 (nil 2 (nil -4 ((nil 7064 nil) 3 nil))))
+	// ((((((((nil -2503553836720 ((((((nil -5 ((nil 50440 (nil 54 (((((((nil -2 nil) 543360606020 nil) 15261837 nil) 10 nil) -442 (((nil 1 nil) -2 ((nil 11 nil) -187307 (nil -198 ((nil -539313 nil) -1811 nil)))) 328732828431481 nil)) -66090341586 (nil -11 nil)) 179745 nil))) 113 nil)) -9898554875447 nil) -34709387 nil) -21034573818 (nil 395928 nil)) 5 nil) 269930359779434858 nil)) -2 nil) -5 nil) -2172865589 nil) -3 nil) -1 nil) -2 nil) -3 nil)
+	// ((((((nil 3 (((((nil -677950 ((nil 3317 (((((nil -27957167 ((nil -244645277 (nil -254026534 nil)) -46 nil)) 50927 (nil 4 nil)) 61 (nil 0 nil)) 78 nil) -3 (nil 1993775377 nil))) -258 (nil 1 nil))) 52 nil) -3115550172599 (nil 140 ((nil 1247 nil) 6 nil))) 14 nil) -21 (nil -879896 nil))) -5843 (nil 33975920899014 nil)) 21 nil) -2 nil) 308 nil) 4 nil)
+	// (((((nil 16 (((nil 2176603 (((((((nil -9 nil) 4 ((nil 1 nil) 946854459892 (((((((nil 19 (nil -119851623 ((nil -33208028 nil) 66 (nil 21 (((((((((nil -1830 ((nil 434 ((nil 1008 nil) -16137 nil)) 1004 nil)) -214 nil) 976615394 nil) 487 nil) 3 (nil 48756 nil)) -1317 nil) -259 (((nil 111 nil) -426 nil) -2 nil)) -3062214 nil) -1 nil))))) 460 nil) -230 nil) -3 nil) -5985 nil) -2610 nil) 0 nil))) -2703222759 nil) -6128 nil) -1 nil) 269 nil) -1 nil)) -62 nil) -75 nil)) -769 nil) -15 nil) -131 nil) 590 nil)
 	// nil
 }
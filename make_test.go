@@ -29,6 +29,32 @@ type kindOverride struct {
 	Alias stringAlias
 }
 
+type recursiveList struct {
+	Value int
+	Next  *recursiveList
+}
+
+type recursiveTree struct {
+	Children []recursiveTree
+	Named    map[string]recursiveTree
+}
+
+type recursiveSlice []recursiveSlice
+
+func TestMakeRecursive(t *testing.T) {
+	t.Parallel()
+
+	t.Run("list", rapid.MakeCheck(func(t *rapid.T) {
+		rapid.Make[*recursiveList]().Draw(t, "l")
+	}))
+	t.Run("tree", rapid.MakeCheck(func(t *rapid.T) {
+		rapid.Make[recursiveTree]().Draw(t, "t")
+	}))
+	t.Run("slice", rapid.MakeCheck(func(t *rapid.T) {
+		rapid.Make[recursiveSlice]().Draw(t, "s")
+	}))
+}
+
 func TestMakeIgnoresPrivateFields(t *testing.T) {
 	// Private fields are ignored (and don't panic).
 	rapid.Make[privateFields]().Example()
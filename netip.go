@@ -0,0 +1,239 @@
+// Copyright 2019 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rapid
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+)
+
+const (
+	addrSpecialProb    = 0.25
+	prefixFullProb     = 0.15
+	prefixUnmaskedProb = 0.25
+
+	addrKindIPv4    = 0
+	addrKindIPv6    = 1
+	addrKindIPv4in6 = 2
+	addrKindZoned   = 3
+)
+
+var (
+	// "This network" must be the first one, for addresses to shrink towards 0.0.0.0.
+	specialPrefixes4 = []netip.Prefix{
+		netip.MustParsePrefix("0.0.0.0/8"),
+		netip.MustParsePrefix("127.0.0.0/8"),
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("172.16.0.0/12"),
+		netip.MustParsePrefix("192.168.0.0/16"),
+		netip.MustParsePrefix("169.254.0.0/16"),
+		netip.MustParsePrefix("100.64.0.0/10"),
+		netip.MustParsePrefix("224.0.0.0/4"),
+		netip.MustParsePrefix("255.255.255.255/32"),
+	}
+
+	// Unspecified address must be the first one, for addresses to shrink towards ::.
+	specialPrefixes6 = []netip.Prefix{
+		netip.MustParsePrefix("::/128"),
+		netip.MustParsePrefix("::1/128"),
+		netip.MustParsePrefix("::ffff:0:0/96"),
+		netip.MustParsePrefix("64:ff9b::/96"),
+		netip.MustParsePrefix("fe80::/10"),
+		netip.MustParsePrefix("fc00::/7"),
+		netip.MustParsePrefix("ff00::/8"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	}
+
+	linkLocalPrefix6 = netip.MustParsePrefix("fe80::/10")
+	addrZones        = []string{"eth0", "en0", "lo", "1"}
+)
+
+// IPv4Addr creates a generator of IPv4 addresses. Generated addresses are biased
+// towards special ranges (like loopback, private, link-local or multicast ones),
+// and shrink towards 0.0.0.0.
+func IPv4Addr() *Generator[netip.Addr] {
+	return newGenerator[netip.Addr](&addrGen{
+		kinds: []int{addrKindIPv4},
+		str:   "IPv4Addr()",
+	})
+}
+
+// IPv6Addr creates a generator of IPv6 addresses without zones. Generated addresses are biased
+// towards special ranges (like loopback, link-local, unique local or multicast ones),
+// and shrink towards ::.
+func IPv6Addr() *Generator[netip.Addr] {
+	return newGenerator[netip.Addr](&addrGen{
+		kinds: []int{addrKindIPv6},
+		str:   "IPv6Addr()",
+	})
+}
+
+// Addr creates a generator of IPv4 and IPv6 addresses, including IPv4-mapped IPv6
+// addresses and IPv6 addresses with zones. Generated addresses shrink towards 0.0.0.0.
+func Addr() *Generator[netip.Addr] {
+	return newGenerator[netip.Addr](&addrGen{
+		kinds: []int{addrKindIPv4, addrKindIPv6, addrKindIPv4in6, addrKindZoned},
+		str:   "Addr()",
+	})
+}
+
+type addrGen struct {
+	kinds []int
+	str   string
+}
+
+func (g *addrGen) String() string {
+	return g.str
+}
+
+func (g *addrGen) value(t *T) netip.Addr {
+	return genAddr(t.s, g.kinds)
+}
+
+// AddrPort creates a generator of address and port pairs, with addresses generated by [Addr].
+func AddrPort() *Generator[netip.AddrPort] {
+	return newGenerator[netip.AddrPort](&addrPortGen{})
+}
+
+type addrPortGen struct{}
+
+func (g *addrPortGen) String() string {
+	return "AddrPort()"
+}
+
+func (g *addrPortGen) value(t *T) netip.AddrPort {
+	addr := genAddr(t.s, []int{addrKindIPv4, addrKindIPv6, addrKindIPv4in6, addrKindZoned})
+	port, _, _ := genUintRange(t.s, 0, 1<<16-1, true)
+
+	return netip.AddrPortFrom(addr, uint16(port))
+}
+
+// Prefix creates a generator of valid IPv4 and IPv6 prefixes. Generated prefixes
+// are biased towards /0 and full-length (/32 and /128) ones, and can have
+// non-zero bits after the prefix (see [netip.Prefix.Masked]).
+// Generated prefixes shrink towards 0.0.0.0/0.
+func Prefix() *Generator[netip.Prefix] {
+	return newGenerator[netip.Prefix](&prefixGen{})
+}
+
+// PrefixIn creates a generator of masked prefixes contained in (or equal to) the
+// masked version of p.
+func PrefixIn(p netip.Prefix) *Generator[netip.Prefix] {
+	assertf(p.IsValid(), "invalid prefix %v", p)
+
+	return newGenerator[netip.Prefix](&prefixGen{
+		parent: p.Masked(),
+		in:     true,
+	})
+}
+
+type prefixGen struct {
+	parent netip.Prefix
+	in     bool
+}
+
+func (g *prefixGen) String() string {
+	if g.in {
+		return fmt.Sprintf("PrefixIn(%v)", g.parent)
+	}
+
+	return "Prefix()"
+}
+
+func (g *prefixGen) value(t *T) netip.Prefix {
+	if g.in {
+		bits := genPrefixBits(t.s, g.parent.Bits(), g.parent.Addr().BitLen())
+		addr := withHostBits(g.parent.Addr(), g.parent.Bits(), genAddrBits(t.s, g.parent.Addr().Is4()))
+		return netip.PrefixFrom(addr, bits).Masked()
+	}
+
+	addr := genAddr(t.s, []int{addrKindIPv4, addrKindIPv6, addrKindIPv4in6})
+	bits := genPrefixBits(t.s, 0, addr.BitLen())
+	p := netip.PrefixFrom(addr, bits)
+	if flipBiasedCoin(t.s, prefixUnmaskedProb) {
+		return p
+	}
+
+	return p.Masked()
+}
+
+func genPrefixBits(s bitStream, min int, max int) int {
+	if flipBiasedCoin(s, prefixFullProb) {
+		return max
+	}
+
+	bits, _, _ := genIntRange(s, int64(min), int64(max), true)
+	return int(bits)
+}
+
+func genAddr(s bitStream, kinds []int) netip.Addr {
+	kind := kinds[0]
+	if len(kinds) > 1 {
+		kind = kinds[genIndex(s, len(kinds), true)]
+	}
+
+	switch kind {
+	case addrKindIPv4:
+		return genSpecialAddr(s, true)
+	case addrKindIPv6:
+		return genSpecialAddr(s, false)
+	case addrKindIPv4in6:
+		return netip.AddrFrom16(genSpecialAddr(s, true).As16())
+	default:
+		addr := withHostBits(linkLocalPrefix6.Addr(), linkLocalPrefix6.Bits(), genAddrBits(s, false))
+		return addr.WithZone(addrZones[genIndex(s, len(addrZones), true)])
+	}
+}
+
+// genSpecialAddr draws the special prefix before the host bits: this way, when the prefix
+// is minimized, the host bits are still random, and full-length prefixes (like the broadcast one)
+// do not become local minima because of their host bits having been shrunk to zero.
+func genSpecialAddr(s bitStream, is4 bool) netip.Addr {
+	prefixes := specialPrefixes6
+	p := netip.PrefixFrom(netip.IPv6Unspecified(), 0)
+	if is4 {
+		prefixes = specialPrefixes4
+		p = netip.PrefixFrom(netip.IPv4Unspecified(), 0)
+	}
+	if flipBiasedCoin(s, addrSpecialProb) {
+		p = prefixes[genIndex(s, len(prefixes), true)]
+	}
+
+	return withHostBits(p.Addr(), p.Bits(), genAddrBits(s, is4))
+}
+
+func genAddrBits(s bitStream, is4 bool) netip.Addr {
+	if is4 {
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], uint32(s.drawBits(32)))
+		return netip.AddrFrom4(b)
+	}
+
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], s.drawBits(64))
+	binary.BigEndian.PutUint64(b[8:], s.drawBits(64))
+	return netip.AddrFrom16(b)
+}
+
+// withHostBits returns the first bits of network, followed by the rest of bits of host.
+func withHostBits(network netip.Addr, bits int, host netip.Addr) netip.Addr {
+	n, h := network.AsSlice(), host.AsSlice()
+	for i := range n {
+		switch {
+		case bits >= (i+1)*8:
+		case bits <= i*8:
+			n[i] = h[i]
+		default:
+			mask := byte(0xff) >> (bits - i*8)
+			n[i] = n[i]&^mask | h[i]&mask
+		}
+	}
+
+	addr, _ := netip.AddrFromSlice(n)
+	return addr
+}
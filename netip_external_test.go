@@ -0,0 +1,141 @@
+// Copyright 2019 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rapid_test
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	. "pgregory.net/rapid"
+)
+
+func TestNetipExamples(t *testing.T) {
+	gens := []*Generator[any]{
+		IPv4Addr().AsAny(),
+		IPv6Addr().AsAny(),
+		Addr().AsAny(),
+		AddrPort().AsAny(),
+		Prefix().AsAny(),
+		PrefixIn(netip.MustParsePrefix("10.0.0.0/8")).AsAny(),
+	}
+
+	for _, g := range gens {
+		t.Run(g.String(), func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				t.Log(g.Example())
+			}
+		})
+	}
+}
+
+func TestAddr(t *testing.T) {
+	t.Parallel()
+
+	Check(t, func(t *T) {
+		a4 := IPv4Addr().Draw(t, "a4")
+		if !a4.Is4() {
+			t.Fatalf("got non-IPv4 address %v", a4)
+		}
+		a6 := IPv6Addr().Draw(t, "a6")
+		if !a6.Is6() || a6.Zone() != "" {
+			t.Fatalf("got non-IPv6 or zoned address %v", a6)
+		}
+		a := Addr().Draw(t, "a")
+		if !a.IsValid() {
+			t.Fatalf("got invalid address %v", a)
+		}
+		a2, err := netip.ParseAddr(a.String())
+		if err != nil || a2 != a {
+			t.Fatalf("address %v does not roundtrip: %v, %v", a, a2, err)
+		}
+	})
+}
+
+func TestAddrSpecials(t *testing.T) {
+	t.Parallel()
+
+	checks := map[string]func(netip.Addr) bool{
+		"unspecified":  netip.Addr.IsUnspecified,
+		"loopback":     netip.Addr.IsLoopback,
+		"private":      netip.Addr.IsPrivate,
+		"link-local":   netip.Addr.IsLinkLocalUnicast,
+		"multicast":    netip.Addr.IsMulticast,
+		"4-in-6":       netip.Addr.Is4In6,
+		"zoned":        func(a netip.Addr) bool { return a.Zone() != "" },
+		"broadcast":    func(a netip.Addr) bool { return a == netip.AddrFrom4([4]byte{255, 255, 255, 255}) },
+		"IPv6 private": func(a netip.Addr) bool { return a.Is6() && !a.Is4In6() && a.IsPrivate() },
+	}
+
+	got := map[string]bool{}
+	g := Addr()
+	for i := 0; i < 10000 && len(got) < len(checks); i++ {
+		a := g.Example(i)
+		for name, check := range checks {
+			if check(a) {
+				got[name] = true
+			}
+		}
+	}
+	for name := range checks {
+		if !got[name] {
+			t.Errorf("no %v addresses generated", name)
+		}
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	t.Parallel()
+
+	var full, zero, unmasked bool
+	Check(t, func(t *T) {
+		p := Prefix().Draw(t, "p")
+		if !p.IsValid() {
+			t.Fatalf("got invalid prefix %v", p)
+		}
+		full = full || p.Bits() == p.Addr().BitLen()
+		zero = zero || p.Bits() == 0
+		unmasked = unmasked || p != p.Masked()
+	})
+
+	if !full || !zero || !unmasked {
+		t.Fatalf("edge cases not generated: full-length %v, /0 %v, unmasked %v", full, zero, unmasked)
+	}
+}
+
+func TestPrefixIn(t *testing.T) {
+	t.Parallel()
+
+	Check(t, func(t *T) {
+		parent := Prefix().Draw(t, "parent")
+		p := PrefixIn(parent).Draw(t, "p")
+		if !p.IsValid() || p != p.Masked() {
+			t.Fatalf("got invalid or unmasked prefix %v", p)
+		}
+		if p.Bits() < parent.Bits() || !parent.Masked().Contains(p.Addr()) {
+			t.Fatalf("got prefix %v which is not contained in %v", p, parent)
+		}
+	})
+}
+
+func TestMakeNetip(t *testing.T) {
+	t.Parallel()
+
+	type endpoint struct {
+		Addr     netip.Addr
+		AddrPort netip.AddrPort
+		Prefix   netip.Prefix
+		IP       net.IP
+	}
+
+	Check(t, func(t *T) {
+		e := Make[endpoint]().Draw(t, "e")
+		if !e.Addr.IsValid() || !e.AddrPort.IsValid() || !e.Prefix.IsValid() || e.IP == nil {
+			t.Fatalf("got invalid endpoint %+v", e)
+		}
+	})
+}
@@ -8,12 +8,13 @@ package rapid
 
 import (
 	"bufio"
+	"encoding/binary"
 	"fmt"
+	"hash/fnv"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
-	"time"
 	"unicode"
 )
 
@@ -22,6 +23,17 @@ const (
 
 	persistDirMode     = 0775
 	failfileTmpPattern = ".rapid-failfile-tmp-*"
+	failfileMaxLine    = 16 << 20
+
+	failfileFormat     = "v2"
+	failfileFormatV1   = "v1"
+	failfileFormatKey  = "format"
+	failfileVersionKey = "rapid"
+	failfileGoKey      = "go"
+	failfileTestKey    = "test"
+	failfileSeedKey    = "seed"
+	failfileDrawKey    = "draw"
+	failfileDataKey    = "data"
 )
 
 var (
@@ -52,20 +64,33 @@ func kindaSafeFilename(f string) string {
 	return name
 }
 
-func failFileName(testName string) (string, string) {
-	ts := time.Now().Format("20060102150405")
-	fileName := fmt.Sprintf("%s-%s-%d.fail", kindaSafeFilename(testName), ts, os.Getpid())
-	dirName := filepath.Join("testdata", "rapid", kindaSafeFilename(testName))
-	return dirName, filepath.Join(dirName, fileName)
+// failFileKey returns the file name and extension for key. Targets are stored next to the
+// fail files of the test with a different extension, as kindaSafeFilename can map their key
+// to the one of another test.
+func failFileKey(key string) (string, string) {
+	if name, ok := strings.CutSuffix(key, targetsKeySuffix); ok {
+		return kindaSafeFilename(name), "targets"
+	}
+	return kindaSafeFilename(key), "fail"
 }
 
-func failFilePattern(testName string) string {
-	fileName := fmt.Sprintf("%s-*.fail", kindaSafeFilename(testName))
-	dirName := filepath.Join("testdata", "rapid", kindaSafeFilename(testName))
-	return filepath.Join(dirName, fileName)
+func failFileName(dir string, key string, buf []uint64) string {
+	h := fnv.New64a()
+	for _, u := range buf {
+		_ = binary.Write(h, binary.LittleEndian, u)
+	}
+	name, ext := failFileKey(key)
+	fileName := fmt.Sprintf("%s-%016x.%s", name, h.Sum64(), ext)
+	return filepath.Join(dir, name, fileName)
 }
 
-func saveFailFile(filename string, version string, output []byte, seed uint64, buf []uint64) error {
+func failFilePattern(dir string, key string) string {
+	name, ext := failFileKey(key)
+	fileName := fmt.Sprintf("%s-*.%s", name, ext)
+	return filepath.Join(dir, name, fileName)
+}
+
+func saveFailFile(filename string, ex Example) error {
 	dir := filepath.Dir(filename)
 	err := os.MkdirAll(dir, persistDirMode)
 	if err != nil {
@@ -79,20 +104,26 @@ func saveFailFile(filename string, version string, output []byte, seed uint64, b
 	defer func() { _ = os.Remove(f.Name()) }()
 	defer func() { _ = f.Close() }()
 
-	out := strings.Split(string(output), "\n")
-	for _, s := range out {
-		_, err := f.WriteString("# " + s + "\n")
-		if err != nil {
-			return fmt.Errorf("failed to write data to fail file %q: %w", filename, err)
-		}
+	var lines []string
+	for _, s := range strings.Split(strings.TrimSuffix(string(ex.Output), "\n"), "\n") {
+		lines = append(lines, "# "+s)
 	}
-
-	bs := []string{fmt.Sprintf("%v#%v", version, seed)}
-	for _, u := range buf {
-		bs = append(bs, fmt.Sprintf("0x%x", u))
+	lines = append(lines,
+		failfileFormatKey+": "+failfileFormat,
+		failfileVersionKey+": "+ex.Version,
+		failfileGoKey+": "+ex.GoVersion,
+		failfileTestKey+": "+strconv.Quote(ex.Test),
+		failfileSeedKey+": "+strconv.FormatUint(ex.Seed, 10),
+	)
+	for _, d := range ex.Draws {
+		lines = append(lines, fmt.Sprintf("%v: %q %q %q", failfileDrawKey, d.Label, d.Generator, d.Value))
+	}
+	lines = append(lines, failfileDataKey+":")
+	for _, u := range ex.Data {
+		lines = append(lines, fmt.Sprintf("0x%x", u))
 	}
 
-	_, err = f.WriteString(strings.Join(bs, "\n"))
+	_, err = f.WriteString(strings.Join(lines, "\n") + "\n")
 	if err != nil {
 		return fmt.Errorf("failed to write data to fail file %q: %w", filename, err)
 	}
@@ -106,47 +137,126 @@ func saveFailFile(filename string, version string, output []byte, seed uint64, b
 	return nil
 }
 
-func loadFailFile(filename string) (string, uint64, []uint64, error) {
+func loadFailFile(filename string) (Example, error) {
 	f, err := os.Open(filename)
 	if err != nil {
-		return "", 0, nil, fmt.Errorf("failed to open fail file: %w", err)
+		return Example{}, fmt.Errorf("failed to open fail file: %w", err)
 	}
 	defer func() { _ = f.Close() }()
 
-	var data []string
+	var (
+		data   []string
+		output []string
+	)
 	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, failfileMaxLine)
 	for scanner.Scan() {
 		s := strings.TrimSpace(scanner.Text())
-		if strings.HasPrefix(s, "#") || s == "" {
+		if strings.HasPrefix(s, "#") {
+			output = append(output, strings.TrimPrefix(strings.TrimPrefix(s, "#"), " "))
+			continue
+		}
+		if s == "" {
 			continue
 		}
 		data = append(data, s)
 	}
 	if err := scanner.Err(); err != nil {
-		return "", 0, nil, fmt.Errorf("failed to load fail file %q: %w", filename, err)
+		return Example{}, fmt.Errorf("failed to load fail file %q: %w", filename, err)
 	}
 
 	if len(data) == 0 {
-		return "", 0, nil, fmt.Errorf("no data in fail file %q", filename)
+		return Example{}, fmt.Errorf("no data in fail file %q", filename)
+	}
+
+	var ex Example
+	if strings.HasPrefix(data[0], failfileFormatKey+":") {
+		ex, data, err = parseFailFileHeader(data)
+	} else {
+		ex, data, err = parseFailFileHeaderV1(data)
+	}
+	if err != nil {
+		return Example{}, fmt.Errorf("invalid fail file %q: %w", filename, err)
+	}
+
+	for _, b := range data {
+		u, err := strconv.ParseUint(b, 0, 64)
+		if err != nil {
+			return Example{}, fmt.Errorf("failed to load fail file %q: %w", filename, err)
+		}
+		ex.Data = append(ex.Data, u)
 	}
+	ex.Output = []byte(strings.Join(output, "\n"))
+	ex.file = filename
 
+	return ex, nil
+}
+
+// parseFailFileHeaderV1 parses "<version>#<seed>" line of the original fail file format.
+func parseFailFileHeaderV1(data []string) (Example, []string, error) {
 	split := strings.Split(data[0], "#")
 	if len(split) != 2 {
-		return "", 0, nil, fmt.Errorf("invalid version/seed field %q in %q", data[0], filename)
+		return Example{}, nil, fmt.Errorf("invalid version/seed field %q", data[0])
 	}
 	seed, err := strconv.ParseUint(split[1], 10, 64)
 	if err != nil {
-		return "", 0, nil, fmt.Errorf("invalid seed %q in %q", split[1], filename)
+		return Example{}, nil, fmt.Errorf("invalid seed %q", split[1])
 	}
 
-	var buf []uint64
-	for _, b := range data[1:] {
-		u, err := strconv.ParseUint(b, 0, 64)
+	return Example{Version: split[0], Seed: seed, format: failfileFormatV1}, data[1:], nil
+}
+
+func parseFailFileHeader(data []string) (Example, []string, error) {
+	var ex Example
+	for i, line := range data {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return Example{}, nil, fmt.Errorf("invalid header line %q", line)
+		}
+		value = strings.TrimSpace(value)
+
+		var err error
+		switch key {
+		case failfileFormatKey:
+			if value != failfileFormat {
+				return Example{}, nil, fmt.Errorf("unsupported format %q", value)
+			}
+			ex.format = value
+		case failfileVersionKey:
+			ex.Version = value
+		case failfileGoKey:
+			ex.GoVersion = value
+		case failfileTestKey:
+			ex.Test, err = strconv.Unquote(value)
+		case failfileSeedKey:
+			ex.Seed, err = strconv.ParseUint(value, 10, 64)
+		case failfileDrawKey:
+			var d ExampleDraw
+			d, err = parseDraw(value)
+			ex.Draws = append(ex.Draws, d)
+		case failfileDataKey:
+			return ex, data[i+1:], nil
+		default:
+			// unknown keys are ignored, for forward compatibility
+		}
+		if err != nil {
+			return Example{}, nil, fmt.Errorf("invalid %v %q: %w", key, value, err)
+		}
+	}
+
+	return Example{}, nil, fmt.Errorf("no %v", failfileDataKey)
+}
+
+func parseDraw(s string) (ExampleDraw, error) {
+	var fields [3]string
+	for i := range fields {
+		q, err := strconv.QuotedPrefix(s)
 		if err != nil {
-			return "", 0, nil, fmt.Errorf("failed to load fail file %q: %w", filename, err)
+			return ExampleDraw{}, err
 		}
-		buf = append(buf, u)
+		fields[i], _ = strconv.Unquote(q)
+		s = strings.TrimSpace(s[len(q):])
 	}
 
-	return split[0], seed, buf, nil
+	return ExampleDraw{Label: fields[0], Generator: fields[1], Value: fields[2]}, nil
 }
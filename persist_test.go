@@ -9,6 +9,7 @@ package rapid
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -17,8 +18,9 @@ func TestFailFilePattern(t *testing.T) {
 
 	Check(t, func(t *T) {
 		testName := String().Draw(t, "testName")
-		_, fileName := failFileName(testName)
-		pattern := failFilePattern(testName)
+		buf := SliceOf(Uint64()).Draw(t, "buf")
+		fileName := failFileName(filepath.Join("testdata", "rapid"), testName, buf)
+		pattern := failFilePattern(filepath.Join("testdata", "rapid"), testName)
 		match, err := filepath.Match(pattern, fileName)
 		if !match || err != nil {
 			t.Fatalf("pattern %q does not match %q; err %v", pattern, fileName, err)
@@ -37,19 +39,24 @@ func TestFailFileRoundtrip(t *testing.T) {
 			seed     = Uint64().Draw(t, "seed")
 			output   = SliceOf(Byte()).Draw(t, "output")
 			buf      = SliceOf(Uint64()).Draw(t, "buf")
+			goVer    = StringMatching(`go[0-9.]+`).Draw(t, "goVer")
+			draws    = SliceOf(Custom(func(t *T) ExampleDraw {
+				return ExampleDraw{Label: String().Draw(t, "label"), Generator: String().Draw(t, "generator"), Value: String().Draw(t, "value")}
+			})).Draw(t, "draws")
 		)
 
-		dirName, fileName := failFileName(testName)
-		err := saveFailFile(fileName, version, output, seed, buf)
+		fileName := failFileName(filepath.Join("testdata", "rapid"), testName, buf)
+		err := saveFailFile(fileName, Example{Version: version, Seed: seed, Data: buf, Output: output, Test: testName, GoVersion: goVer, Draws: draws})
 		if err != nil {
 			t.Fatal(err)
 		}
-		defer func() { _ = os.RemoveAll(dirName) }()
+		defer func() { _ = os.RemoveAll(filepath.Dir(fileName)) }()
 
-		version2, seed2, buf2, err := loadFailFile(fileName)
+		ex, err := loadFailFile(fileName)
 		if err != nil {
 			t.Fatal(err)
 		}
+		version2, seed2, buf2 := ex.Version, ex.Seed, ex.Data
 
 		if version2 != version {
 			t.Fatalf("got version %q instead of %q", version2, version)
@@ -57,6 +64,12 @@ func TestFailFileRoundtrip(t *testing.T) {
 		if seed2 != seed {
 			t.Fatalf("got seed %v instead of %v", seed2, seed)
 		}
+		if ex.Test != testName || ex.GoVersion != goVer {
+			t.Fatalf("got test %q and go version %q instead of %q and %q", ex.Test, ex.GoVersion, testName, goVer)
+		}
+		if !reflect.DeepEqual(ex.Draws, draws) && (len(ex.Draws) > 0 || len(draws) > 0) {
+			t.Fatalf("got draws %q instead of %q", ex.Draws, draws)
+		}
 		if len(buf2) != len(buf) {
 			t.Fatalf("got buf of length %v instead of %v", len(buf2), len(buf))
 		}
@@ -67,3 +80,21 @@ func TestFailFileRoundtrip(t *testing.T) {
 		}
 	})
 }
+
+func TestFailFileV1(t *testing.T) {
+	t.Parallel()
+
+	fileName := filepath.Join(t.TempDir(), "v1.fail")
+	err := os.WriteFile(fileName, []byte("# 2020/01/01 [TestFoo] [rapid] draw n: 1\nv0.4.0#42\n0x1\n0x2"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ex, err := loadFailFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ex.Version != "v0.4.0" || ex.Seed != 42 || !reflect.DeepEqual(ex.Data, []uint64{1, 2}) || ex.format != failfileFormatV1 {
+		t.Fatalf("unexpected example %+v", ex)
+	}
+}
@@ -0,0 +1,516 @@
+// Copyright 2019 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rapid
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"hash/fnv"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Replay runs prop once, with the values returned by [Generator.Draw] pinned
+// to draws, in order. Pinned values must have the exact types of the values
+// their generators produce. Inside [T.Repeat], actions are executed
+// as long as there are pinned values left.
+//
+// Replay is intended for plain regression tests, which can be generated
+// from failures found by [Check] using -rapid.emit flag. The tests refer to
+// the property by name, so they are generated only when it is a top-level
+// function, not a closure or a method value.
+func Replay(t TB, prop func(*T), draws ...any) {
+	t.Helper()
+
+	rt := newT(t, newBufBitStream(nil, false), true, nil)
+	rt.pinned = draws
+	rt.replay = true
+	if replayOnce(t, rt, prop) && rt.draws < len(draws) {
+		t.Errorf("[rapid] only %v of %v pinned values have been drawn", rt.draws, len(draws))
+	}
+}
+
+// ReplayFile runs prop once, using the data of the fail file filename.
+// It is used by the regression tests generated using -rapid.emit flag
+// for the values which can not be written as Go literals.
+func ReplayFile(t TB, prop func(*T), filename string) {
+	t.Helper()
+
+	ex, err := loadFailFile(filename)
+	if err != nil {
+		t.Fatalf("[rapid] %v", err)
+	}
+
+	rt := newT(t, newBufBitStream(ex.Data, false), true, nil)
+	_ = replayOnce(t, rt, prop)
+}
+
+func replayOnce(t TB, rt *T, prop func(*T)) bool {
+	t.Helper()
+
+	err := checkOnce(rt, prop)
+	switch {
+	case err == nil:
+		return true
+	case err.isInvalidData():
+		t.Fatalf("[rapid] can not replay test case: %v", err)
+	case err.isStopTest():
+		t.Fatalf("[rapid] failed: %v", err)
+	default:
+		t.Fatalf("[rapid] panic: %v\nTraceback:\n%v", err, traceback(err))
+	}
+
+	return false
+}
+
+func pinnedValue[V any](t *T, g *Generator[V]) V {
+	if t.draws >= len(t.pinned) {
+		t.Fatalf("[rapid] no pinned value for draw %v from %v", t.draws, g)
+	}
+
+	v, ok := t.pinned[t.draws].(V)
+	if !ok {
+		var zero V
+		t.Fatalf("[rapid] pinned value %#v for draw %v is not of type %T", t.pinned[t.draws], t.draws, zero)
+	}
+
+	return v
+}
+
+// literalWriter formats values as Go expressions for use in package pkgPath.
+type literalWriter struct {
+	pkgPath string
+	names   map[string]string // package path -> name it is imported with
+	imports map[string]bool   // packages the expressions refer to
+	visited map[uintptr]bool  // pointers being formatted, to detect cycles
+}
+
+func newLiteralWriter(pkgPath string, names map[string]string) *literalWriter {
+	return &literalWriter{
+		pkgPath: pkgPath,
+		names:   names,
+		imports: map[string]bool{},
+		visited: map[uintptr]bool{},
+	}
+}
+
+// goLiteral formats v as a Go expression of the same type. It fails for values
+// which can not be constructed by a Go expression, like the ones of types
+// with unexported fields from other packages.
+func goLiteral(v any, w *literalWriter) (string, bool) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return "", false // nil interfaces can not be pinned
+	}
+
+	return w.value(rv, true)
+}
+
+// qualify returns the name of a package-level identifier, as used in w.pkgPath.
+func (w *literalWriter) qualify(pkgPath string, pkgName string, name string) string {
+	if pkgPath == w.pkgPath {
+		return name
+	}
+	w.imports[pkgPath] = true
+	if n, ok := w.names[pkgPath]; ok {
+		pkgName = n
+	}
+	if pkgName == "." {
+		return name
+	}
+
+	return pkgName + "." + name
+}
+
+func (w *literalWriter) typ(typ reflect.Type) (string, bool) {
+	if name := typ.Name(); name != "" {
+		switch {
+		case typ.PkgPath() == "":
+			return name, true
+		case strings.Contains(name, "["):
+			return "", false // instantiated generic types are not supported
+		case typ.PkgPath() != w.pkgPath && !token.IsExported(name):
+			return "", false
+		}
+		return w.qualify(typ.PkgPath(), strings.TrimSuffix(typ.String(), "."+name), name), true
+	}
+
+	switch typ.Kind() {
+	case reflect.Pointer:
+		elem, ok := w.typ(typ.Elem())
+		return "*" + elem, ok
+	case reflect.Slice:
+		if typ.Elem() == reflect.TypeFor[byte]() {
+			return "[]byte", true
+		}
+		elem, ok := w.typ(typ.Elem())
+		return "[]" + elem, ok
+	case reflect.Array:
+		elem, ok := w.typ(typ.Elem())
+		return fmt.Sprintf("[%v]%v", typ.Len(), elem), ok
+	case reflect.Map:
+		key, ok1 := w.typ(typ.Key())
+		elem, ok2 := w.typ(typ.Elem())
+		return fmt.Sprintf("map[%v]%v", key, elem), ok1 && ok2
+	case reflect.Interface:
+		return "any", typ.NumMethod() == 0
+	case reflect.Struct:
+		fields := make([]string, typ.NumField())
+		for i := range fields {
+			f := typ.Field(i)
+			if !f.IsExported() && f.PkgPath != w.pkgPath {
+				return "", false
+			}
+			ft, ok := w.typ(f.Type)
+			if !ok {
+				return "", false
+			}
+			fields[i] = ft
+			if !f.Anonymous {
+				fields[i] = f.Name + " " + ft
+			}
+			if f.Tag != "" {
+				fields[i] += " " + strconv.Quote(string(f.Tag))
+			}
+		}
+		return "struct{ " + strings.Join(fields, "; ") + " }", true
+	default:
+		return "", false
+	}
+}
+
+// value formats rv as a Go expression. When typed is false, the type
+// of the expression is implied by the context (like in composite literals),
+// and the conversion of untyped constants to it is omitted.
+func (w *literalWriter) value(rv reflect.Value, typed bool) (string, bool) {
+	typ := rv.Type()
+	if !w.validType(typ) {
+		return "", false
+	}
+	typName := func() string {
+		s, _ := w.typ(typ) // only for the types actually used, to import only the packages needed
+		return s
+	}
+
+	var (
+		s     string
+		exact bool // the default type of untyped constant s is typ
+	)
+	switch rv.Kind() {
+	case reflect.Bool:
+		s, exact = strconv.FormatBool(rv.Bool()), typ.Name() == "bool" && typ.PkgPath() == ""
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s, exact = strconv.FormatInt(rv.Int(), 10), typ.Name() == "int" && typ.PkgPath() == ""
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		s, exact = fmt.Sprintf("%#x", rv.Uint()), false
+	case reflect.String:
+		s, exact = strconv.Quote(rv.String()), typ.Name() == "string" && typ.PkgPath() == ""
+	case reflect.Float32, reflect.Float64:
+		var constant bool
+		s, constant = w.float(rv.Float(), typ.Bits())
+		if !constant {
+			return w.convert(s, typ, reflect.TypeFor[float64]()), true
+		}
+		exact = false
+	case reflect.Complex64, reflect.Complex128:
+		c := rv.Complex()
+		re, reConstant := w.float(real(c), typ.Bits()/2)
+		im, imConstant := w.float(imag(c), typ.Bits()/2)
+		if !reConstant || !imConstant {
+			return w.convert(fmt.Sprintf("complex(%v, %v)", re, im), typ, reflect.TypeFor[complex128]()), true
+		}
+		s, exact = strconv.FormatComplex(c, 'g', -1, typ.Bits()), false
+	case reflect.Slice, reflect.Map:
+		if rv.IsNil() {
+			return typName() + "(nil)", true
+		}
+		return w.composite(rv)
+	case reflect.Array, reflect.Struct:
+		return w.composite(rv)
+	case reflect.Pointer:
+		switch {
+		case rv.IsNil():
+			return "(" + typName() + ")(nil)", true
+		case rv.Elem().Kind() != reflect.Struct || w.visited[rv.Pointer()]:
+			return "", false
+		}
+		w.visited[rv.Pointer()] = true
+		defer delete(w.visited, rv.Pointer())
+		s, ok := w.value(rv.Elem(), true)
+		if !ok {
+			return "", false
+		}
+		return "&" + s, true
+	case reflect.Interface:
+		if rv.IsNil() {
+			return "nil", true
+		}
+		return w.value(rv.Elem(), true)
+	default:
+		return "", false
+	}
+
+	if typed && !exact {
+		return typName() + "(" + s + ")", true
+	}
+	return s, true
+}
+
+// float formats f as a Go expression, and reports if it is a constant.
+func (w *literalWriter) float(f float64, bits int) (string, bool) {
+	switch {
+	case math.IsNaN(f):
+		return w.qualify("math", "math", "NaN()"), false
+	case math.IsInf(f, 1):
+		return w.qualify("math", "math", "Inf(1)"), false
+	case math.IsInf(f, -1):
+		return w.qualify("math", "math", "Inf(-1)"), false
+	case f == 0 && math.Signbit(f):
+		return w.qualify("math", "math", "Copysign(0, -1)"), false
+	default:
+		return strconv.FormatFloat(f, 'g', -1, bits), true
+	}
+}
+
+// validType reports if typ can be referred to in w.pkgPath.
+func (w *literalWriter) validType(typ reflect.Type) bool {
+	_, ok := newLiteralWriter(w.pkgPath, w.names).typ(typ)
+	return ok
+}
+
+// convert converts expression s of type sType to typ, if they differ.
+func (w *literalWriter) convert(s string, typ reflect.Type, sType reflect.Type) string {
+	if typ == sType {
+		return s
+	}
+	typName, _ := w.typ(typ)
+	return typName + "(" + s + ")"
+}
+
+func (w *literalWriter) composite(rv reflect.Value) (string, bool) {
+	var elems []string
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			e, ok := w.value(rv.Index(i), false)
+			if !ok {
+				return "", false
+			}
+			elems = append(elems, e)
+		}
+	case reflect.Map:
+		for iter := rv.MapRange(); iter.Next(); {
+			k, ok1 := w.value(iter.Key(), false)
+			v, ok2 := w.value(iter.Value(), false)
+			if !ok1 || !ok2 {
+				return "", false
+			}
+			elems = append(elems, k+": "+v)
+		}
+		sort.Strings(elems)
+	case reflect.Struct:
+		typ := rv.Type()
+		for i := 0; i < rv.NumField(); i++ {
+			f := typ.Field(i)
+			if !f.IsExported() && f.PkgPath != w.pkgPath {
+				return "", false
+			}
+			if rv.Field(i).IsZero() {
+				continue
+			}
+			e, ok := w.value(rv.Field(i), false)
+			if !ok {
+				return "", false
+			}
+			elems = append(elems, f.Name+": "+e)
+		}
+	}
+
+	typName, _ := w.typ(rv.Type())
+	return typName + "{" + strings.Join(elems, ", ") + "}", true
+}
+
+// emitRegressionTest appends a test replaying the draws of a failing test case to filename.
+// When some of the values drawn can not be written as Go literals, the test replays failfile instead.
+func emitRegressionTest(filename string, testName string, prop func(*T), msg string, draws []ExampleDraw, failfile string) error {
+	pkgPath, pkgName, propName := funcName(prop)
+	if propName == "" {
+		return fmt.Errorf("failed to emit regression test: property of %v is not a top-level function", testName)
+	}
+
+	src, err := os.ReadFile(filename)
+	switch {
+	case err == nil:
+	case errors.Is(err, os.ErrNotExist):
+		if name := packageName(filepath.Dir(filename), strings.HasSuffix(pkgPath, "_test")); name != "" {
+			pkgName = name
+		}
+		src = []byte(fmt.Sprintf("package %v\n", pkgName))
+	default:
+		return fmt.Errorf("failed to emit regression test: %w", err)
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.ImportsOnly)
+	if err != nil {
+		return fmt.Errorf("failed to emit regression test: %w", err)
+	}
+	names := map[string]string{}
+	for _, spec := range f.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if spec.Name == nil {
+			names[path] = path[strings.LastIndex(path, "/")+1:]
+		} else if spec.Name.Name != "_" {
+			names[path] = spec.Name.Name
+		}
+	}
+
+	w := newLiteralWriter(pkgPath, names)
+	lits := make([]string, len(draws))
+	for i, d := range draws {
+		lit, ok := goLiteral(d.v, w)
+		if !ok {
+			if failfile == "" {
+				return fmt.Errorf("failed to emit regression test: %v = %v can not be written as a Go literal, and there is no fail file to replay", d.Label, d.Value)
+			}
+			w, lits = newLiteralWriter(pkgPath, names), nil
+			break
+		}
+		lits[i] = lit
+	}
+	rapidPath := reflect.TypeFor[T]().PkgPath()
+	replay := w.qualify(rapidPath, "rapid", "Replay")
+	replayFile := w.qualify(rapidPath, "rapid", "ReplayFile")
+	tType := w.qualify("testing", "testing", "T")
+
+	h := fnv.New32a()
+	if lits == nil {
+		_, _ = h.Write([]byte(failfile))
+	}
+	for _, lit := range lits {
+		_, _ = h.Write([]byte(lit))
+	}
+	fn := fmt.Sprintf("%v_Regression_%08x", kindaSafeFilename(testName), h.Sum32())
+	if bytes.Contains(src, []byte("func "+fn+"(")) {
+		return nil // the same failure has already been emitted
+	}
+
+	var b bytes.Buffer
+	b.Write(addImports(fset, f, src, w.imports, names))
+	fmt.Fprintf(&b, "\n// %v failed with: %v\n", testName, strings.ReplaceAll(msg, "\n", "\n// "))
+	fmt.Fprintf(&b, "func %v(t *%v) {\n", fn, tType)
+	if lits == nil {
+		fmt.Fprintf(&b, "\t%v(t, %v, %q)\n", replayFile, propName, filepath.ToSlash(failfile))
+	} else {
+		fmt.Fprintf(&b, "\t%v(t, %v,\n", replay, propName)
+		for i, d := range draws {
+			fmt.Fprintf(&b, "\t\t%v, // %v\n", lits[i], d.Label)
+		}
+		b.WriteString("\t)\n")
+	}
+	b.WriteString("}\n")
+
+	out, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to emit regression test: %w", err)
+	}
+
+	err = os.WriteFile(filename, out, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to emit regression test: %w", err)
+	}
+
+	return nil
+}
+
+// addImports returns src of file f with the imports of paths which are not imported yet.
+func addImports(fset *token.FileSet, f *ast.File, src []byte, paths map[string]bool, names map[string]string) []byte {
+	var missing []string
+	for path := range paths {
+		if _, ok := names[path]; !ok {
+			missing = append(missing, path)
+		}
+	}
+	if len(missing) == 0 {
+		return src
+	}
+	sort.Strings(missing)
+
+	var specs strings.Builder
+	for _, path := range missing {
+		fmt.Fprintf(&specs, "\t%q\n", path)
+	}
+
+	var last *ast.GenDecl
+	for _, decl := range f.Decls {
+		if d, ok := decl.(*ast.GenDecl); ok && d.Tok == token.IMPORT {
+			last = d
+		}
+	}
+
+	var pos int
+	var ins string
+	switch {
+	case last == nil:
+		pos, ins = fset.Position(f.Name.End()).Offset, "\n\nimport (\n"+specs.String()+")\n"
+	case last.Lparen.IsValid():
+		pos, ins = fset.Position(last.Rparen).Offset, specs.String()
+	default:
+		pos, ins = fset.Position(last.End()).Offset, "\n\nimport (\n"+specs.String()+")\n"
+	}
+
+	out := make([]byte, 0, len(src)+len(ins))
+	out = append(out, src[:pos]...)
+	out = append(out, ins...)
+	return append(out, src[pos:]...)
+}
+
+// packageName returns the package name of the Go files in dir, of the external test package
+// if test is set. It returns an empty string when there are no such files.
+func packageName(dir string, test bool) string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range files {
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.PackageClauseOnly)
+		if err == nil && strings.HasSuffix(f.Name.Name, "_test") == test {
+			return f.Name.Name
+		}
+	}
+
+	return ""
+}
+
+// funcName returns the package path of fn, a guess of its package name from the path,
+// and the name of fn if it is a named top-level function.
+func funcName(fn any) (string, string, string) {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return "main", "main", ""
+	}
+
+	name := f.Name() // e.g. "example.com/pkg.TestFoo.func1" or "example.com/pkg.prop"
+	dir := ""
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		dir, name = name[:i+1], name[i+1:]
+	}
+	pkg, fun, _ := strings.Cut(name, ".")
+	if strings.Contains(fun, ".") {
+		fun = ""
+	}
+	pkg = strings.ReplaceAll(pkg, "%2e", ".") // dots in the last path element are escaped
+
+	return dir + pkg, pkg, fun
+}
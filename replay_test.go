@@ -0,0 +1,283 @@
+// Copyright 2020 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rapid
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"math"
+	"math/big"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failureTB is a TB that records errors instead of reporting them.
+type failureTB struct {
+	TB
+	failures []string
+}
+
+func (tb *failureTB) Errorf(format string, args ...any) {
+	tb.failures = append(tb.failures, fmt.Sprintf(format, args...))
+}
+
+func (tb *failureTB) Fatalf(format string, args ...any) {
+	tb.failures = append(tb.failures, fmt.Sprintf(format, args...))
+}
+
+func replayProp(t *T) {
+	n := IntRange(0, 10).Draw(t, "n")
+	s := SliceOfN(Byte(), 1, -1).Draw(t, "s")
+	if n == 3 && len(s) == 2 {
+		t.Fatalf("n = %v, s = %v", n, s)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	t.Parallel()
+
+	Replay(t, replayProp, 4, []byte{1, 2})
+
+	testData := []struct {
+		name  string
+		draws []any
+		msg   string
+	}{
+		{"failure", []any{3, []byte{1, 2}}, "n = 3, s = [1 2]"},
+		{"wrong type", []any{3, "s"}, "is not of type []uint8"},
+		{"too few values", []any{3}, "no pinned value for draw 1"},
+		{"too many values", []any{3, []byte{1}, 5}, "only 2 of 3 pinned values"},
+	}
+
+	for _, td := range testData {
+		t.Run(td.name, func(t *testing.T) {
+			tb := &failureTB{TB: t}
+			Replay(tb, replayProp, td.draws...)
+			if len(tb.failures) != 1 || !strings.Contains(tb.failures[0], td.msg) {
+				t.Fatalf("got failures %q, want %q", tb.failures, td.msg)
+			}
+		})
+	}
+}
+
+func TestReplay_Repeat(t *testing.T) {
+	t.Parallel()
+
+	var steps []string
+	prop := func(t *T) {
+		steps = nil
+		t.Repeat(map[string]func(*T){
+			"a": func(t *T) { steps = append(steps, fmt.Sprintf("a%v", Int().Draw(t, "i"))) },
+			"b": func(t *T) { steps = append(steps, "b") },
+		})
+	}
+
+	Replay(t, prop, "b", "a", 1, "a", 2, "b")
+	if got := strings.Join(steps, " "); got != "b a1 a2 b" {
+		t.Fatalf("got steps %q instead of %q", got, "b a1 a2 b")
+	}
+}
+
+type (
+	replayColor uint8
+	replayName  string
+	replayPoint struct {
+		X int
+		y int
+		D time.Duration
+	}
+)
+
+func TestGoLiteral(t *testing.T) {
+	t.Parallel()
+
+	testData := []struct {
+		v       any
+		lit     string
+		imports string
+	}{
+		{1, "1", ""},
+		{true, "true", ""},
+		{"a\nb", `"a\nb"`, ""},
+		{int8(-1), "int8(-1)", ""},
+		{uint64(7), "uint64(0x7)", ""},
+		{3.5, "float64(3.5)", ""},
+		{'x', "int32(120)", ""},
+		{complex64(1 + 2i), "complex64((1+2i))", ""},
+		{replayColor(2), "replayColor(0x2)", ""},
+		{replayName("n"), `replayName("n")`, ""},
+		{[]int{1, 2}, "[]int{1, 2}", ""},
+		{[]byte{5, 6}, "[]byte{0x5, 0x6}", ""},
+		{[]replayColor(nil), "[]replayColor(nil)", ""},
+		{map[string]int{"b": 2, "a": 1}, `map[string]int{"a": 1, "b": 2}`, ""},
+		{[]any{1, "a", nil}, `[]any{1, "a", nil}`, ""},
+		{time.Duration(5), "time.Duration(5)", "time"},
+		{math.NaN(), "math.NaN()", "math"},
+		{float32(math.Inf(-1)), "float32(math.Inf(-1))", "math"},
+		{[]float64{math.Copysign(0, -1), 1}, "[]float64{math.Copysign(0, -1), 1}", "math"},
+		{complex(math.Inf(1), 1), "complex(math.Inf(1), 1)", "math"},
+		{replayPoint{X: 1, y: 2}, "replayPoint{X: 1, y: 2}", ""},
+		{&replayPoint{D: 3}, "&replayPoint{D: 3}", ""},
+		{(*replayPoint)(nil), "(*replayPoint)(nil)", ""},
+		{struct{ A []time.Duration }{}, "struct{ A []time.Duration }{}", "time"},
+		{big.NewInt(1), "", ""},
+		{netip.IPv6Loopback(), "", ""},
+		{[]time.Time{{}}, "", ""},
+		{func() {}, "", ""},
+	}
+
+	for _, td := range testData {
+		w := newLiteralWriter("pgregory.net/rapid", map[string]string{})
+		lit, ok := goLiteral(td.v, w)
+		var imports []string
+		for path := range w.imports {
+			imports = append(imports, path)
+		}
+		if lit != td.lit || ok != (td.lit != "") || strings.Join(imports, " ") != td.imports {
+			t.Errorf("got literal %q (%v) importing %q for %#v instead of %q importing %q", lit, ok, imports, td.v, td.lit, td.imports)
+		}
+	}
+}
+
+func TestReplayFile(t *testing.T) {
+	t.Parallel()
+
+	prop := func(t *T) {
+		n := IntRange(0, 10).Draw(t, "n")
+		t.Fatalf("n = %v", n)
+	}
+
+	tr := newT(t, newRandomBitStream(baseSeed(), true), false, nil)
+	tr.recordDraws = true
+	_ = checkOnce(tr, prop)
+
+	filename := filepath.Join(t.TempDir(), "test.fail")
+	if err := saveFailFile(filename, Example{Version: rapidVersion, Data: tr.s.(*randomBitStream).data}); err != nil {
+		t.Fatal(err)
+	}
+
+	tb := &failureTB{TB: t}
+	ReplayFile(tb, prop, filename)
+	if msg := "n = " + tr.drawLog[0].Value; len(tb.failures) != 1 || !strings.Contains(tb.failures[0], msg) {
+		t.Fatalf("got failures %q, want %q", tb.failures, msg)
+	}
+}
+
+func TestEmitRegressionTest(t *testing.T) {
+	t.Parallel()
+
+	draws := []ExampleDraw{
+		{Label: "n", v: 3},
+		{Label: "s", v: []byte{5, 6}},
+	}
+	msg := "n = 3, s = [5 6]"
+
+	filename := filepath.Join(t.TempDir(), "regression_test.go")
+	if err := os.WriteFile(filename, []byte("package rapid\n\nimport \"testing\"\n\nfunc TestExisting(t *testing.T) {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := emitRegressionTest(filename, "TestFoo/bar", replayProp, msg, draws, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	more := []ExampleDraw{
+		{Label: "d", v: time.Duration(1)},
+		{Label: "f", v: math.NaN()},
+		{Label: "p", v: replayPoint{X: 1}},
+	}
+	if err := emitRegressionTest(filename, "TestBaz", replayProp, "baz", more, ""); err != nil {
+		t.Fatal(err)
+	}
+	addr := []ExampleDraw{{Label: "a", Value: "netip.Addr{}", v: netip.Addr{}}}
+	if err := emitRegressionTest(filename, "TestQux", replayProp, "qux", addr, ""); err == nil {
+		t.Error("no error emitting a value without Go literal and without a fail file")
+	}
+	if err := emitRegressionTest(filename, "TestQux", replayProp, "qux", addr, "testdata/rapid/TestQux/TestQux-1.fail"); err != nil {
+		t.Fatal(err)
+	}
+	if err := emitRegressionTest(filename, "TestClosure", func(*T) {}, "closure", draws, ""); err == nil {
+		t.Error("no error emitting a test for a closure property")
+	}
+
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"Replay(t, replayProp,",
+		"[]byte{0x5, 0x6}, // s",
+		"time.Duration(1),",
+		"math.NaN(),",
+		"replayPoint{X: 1},",
+		`ReplayFile(t, replayProp, "testdata/rapid/TestQux/TestQux-1.fail")`,
+		"// TestFoo/bar failed with: " + msg,
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Errorf("emitted test does not contain %q:\n%s", s, src)
+		}
+	}
+	if strings.Contains(string(src), "TestClosure") {
+		t.Errorf("emitted test for a closure property:\n%s", src)
+	}
+
+	// type check the emitted test together with the package, and the property it refers to
+	pkg, err := build.ImportDir(".", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range append(pkg.GoFiles, "replay_test.go", filename) {
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatalf("failed to parse %v: %v\n%s", name, err, src)
+		}
+		files = append(files, f)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check(pkg.ImportPath, fset, files, nil); err != nil {
+		t.Fatalf("emitted test does not type check: %v\n%s", err, src)
+	}
+}
+
+func TestEmitRegressionTest_PackageName(t *testing.T) {
+	t.Parallel()
+
+	for _, td := range []struct {
+		sibling string
+		want    string
+	}{
+		{"package other\n", "package other\n"},
+		{"package other_test\n", "package rapid\n"},
+	} {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "other.go"), []byte(td.sibling), 0644); err != nil {
+			t.Fatal(err)
+		}
+		filename := filepath.Join(dir, "regression_test.go")
+		if err := emitRegressionTest(filename, "TestFoo", replayProp, "foo", []ExampleDraw{{Label: "n", v: 3}}, ""); err != nil {
+			t.Fatal(err)
+		}
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(src), td.want) {
+			t.Errorf("emitted test next to %q does not start with %q:\n%s", td.sibling, td.want, src)
+		}
+	}
+}
@@ -28,43 +28,89 @@ const (
 	labelRemoveGroup         = "remove_group"
 	labelRemoveGroupAndLower = "remove_group_lower"
 	labelRemoveGroupSpan     = "remove_groupspan"
+	labelRemoveGroupPair     = "remove_grouppair"
+	labelRedrawBind          = "redraw_bind"
 	labelSortGroups          = "sort_groups"
+
+	redrawBindTries   = 4   // per lowered block
+	maxBindRedraws    = 256 // per shrink, as every re-draw is a random guess
+	maxGroupPairTries = 512 // per pass, as the number of pairs grows quadratically
 )
 
-func shrink(tb tb, deadline time.Time, rec recordedBits, err *testError, prop func(*T)) ([]uint64, *testError) {
+// shrink minimizes the failing test case rec. While doing so, it tracks all distinct
+// errors (by traceback) it stumbles upon and which are not in seen yet,
+// and minimizes each of them in turn. The first of the returned failures
+// always corresponds to err.
+func shrink(tb tb, deadline time.Time, cfg *cmdline, rec recordedBits, err *testError, prop func(*T), seen map[string]struct{}) []failure {
+	seen[traceback(err)] = struct{}{}
+
+	s := newShrinker(tb, cfg, rec, err, prop, seen)
+	buf, err2 := s.shrink(deadline)
+	s.writeVis()
+
+	failures := []failure{{buf: buf, err1: err, err2: err2}}
+	for queue := s.found; len(queue) > 0; queue = queue[1:] {
+		f := queue[0]
+
+		s2 := newBufBitStream(f.buf, true)
+		t := newT(tb, s2, flags.debug && flags.verbose, nil)
+		t.cfg = cfg
+		err1 := checkOnce(t, prop)
+		if !sameError(f.err1, err1) {
+			s.debugf(false, "ignoring flaky error %q", f.err1)
+			continue
+		}
+
+		s.debugf(false, "minimizing distinct error %q", err1)
+		p := newShrinker(tb, cfg, s2.recordedBits, err1, prop, seen)
+		buf, err2 := p.shrink(deadline)
+		failures = append(failures, failure{buf: buf, err1: err1, err2: err2})
+		queue = append(queue, p.found...)
+	}
+
+	return failures
+}
+
+func newShrinker(tb tb, cfg *cmdline, rec recordedBits, err *testError, prop func(*T), seen map[string]struct{}) *shrinker {
 	rec.prune()
 
 	s := &shrinker{
 		tb:      tb,
+		cfg:     cfg,
 		rec:     rec,
 		err:     err,
 		prop:    prop,
 		visBits: []recordedBits{rec},
 		tries:   map[string]int{},
 		cache:   map[string]struct{}{},
+		seen:    seen,
 	}
+	s.rng.init(uint64(len(rec.data))) // deterministic, for shrinking to be reproducible
 
-	buf, err := s.shrink(deadline)
+	return s
+}
 
-	if flags.debugvis {
-		name := fmt.Sprintf("vis-%v.html", strings.Replace(tb.Name(), "/", "_", -1))
-		f, err := os.Create(name)
-		if err != nil {
-			tb.Logf("failed to create debugvis file %v: %v", name, err)
-		} else {
-			defer func() { _ = f.Close() }()
+func (s *shrinker) writeVis() {
+	if !flags.debugvis {
+		return
+	}
 
-			if err = visWriteHTML(f, tb.Name(), s.visBits); err != nil {
-				tb.Logf("failed to write debugvis file %v: %v", name, err)
-			}
+	name := fmt.Sprintf("vis-%v.html", strings.Replace(s.tb.Name(), "/", "_", -1))
+	f, err := os.Create(name)
+	if err != nil {
+		s.tb.Logf("failed to create debugvis file %v: %v", name, err)
+	} else {
+		defer func() { _ = f.Close() }()
+
+		if err = visWriteHTML(f, s.tb.Name(), s.visBits); err != nil {
+			s.tb.Logf("failed to write debugvis file %v: %v", name, err)
 		}
 	}
-
-	return buf, err
 }
 
 type shrinker struct {
 	tb      tb
+	cfg     *cmdline
 	rec     recordedBits
 	err     *testError
 	prop    func(*T)
@@ -73,6 +119,10 @@ type shrinker struct {
 	shrinks int
 	cache   map[string]struct{}
 	hits    int
+	seen    map[string]struct{} // tracebacks of all distinct errors encountered
+	found   []failure           // distinct errors encountered while shrinking
+	rng     jsf64ctx            // for re-drawing the data of Bind groups
+	redraws int                 // Bind re-draws tried so far
 }
 
 func (s *shrinker) debugf(verbose_ bool, format string, args ...any) {
@@ -103,6 +153,8 @@ func (s *shrinker) shrink(deadline time.Time) (buf []uint64, err *testError) {
 			s.removeGroupsAndLower(deadline)
 			s.sortGroups(deadline)
 			s.removeGroupSpans(deadline)
+			s.removeGroupPairs(deadline)
+			s.redrawBindGroups(deadline)
 		}
 	}
 
@@ -245,6 +297,84 @@ func (s *shrinker) removeGroupSpans(deadline time.Time) {
 	}
 }
 
+// removeGroupPairs tries to remove [T.Repeat] steps together with later steps of the same
+// repeat (like a state machine action together with a later action which depends on it).
+func (s *shrinker) removeGroupPairs(deadline time.Time) {
+	tries := 0
+	for i := 0; i < len(s.rec.groups) && tries < maxGroupPairTries && time.Now().Before(deadline); i++ {
+		g := s.rec.groups[i]
+		if !g.standalone || g.end < 0 || !strings.HasSuffix(g.label, repeatLabel) {
+			continue
+		}
+
+		for j := i + 1; j < len(s.rec.groups) && tries < maxGroupPairTries && time.Now().Before(deadline); j++ {
+			h := s.rec.groups[j]
+			if !h.standalone || h.end < 0 || h.begin < g.end || h.label != g.label {
+				continue
+			}
+
+			tries++
+			if s.accept(without(s.rec.data, g, h), labelRemoveGroupPair, "remove group %q at %v: [%v, %v) and group %q at %v: [%v, %v)", g.label, i, g.begin, g.end, h.label, j, h.begin, h.end) {
+				i--
+				break
+			}
+		}
+	}
+}
+
+// redrawBindGroups tries to lower the blocks of the values [Bind] groups depend on
+// (and the blocks of the groups themselves), re-drawing the rest of the group instead of
+// reinterpreting its data, which may have been minimized while irrelevant for the old values.
+func (s *shrinker) redrawBindGroups(deadline time.Time) {
+	for i := 0; i < len(s.rec.groups) && s.redraws < maxBindRedraws && time.Now().Before(deadline); i++ {
+		g := s.rec.groups[i]
+		if g.label != bindLabel || g.end < 0 {
+			continue
+		}
+
+		begin := g.begin
+		for j := i - 1; j >= 0; j-- {
+			h := s.rec.groups[j]
+			if h.standalone && h.begin <= g.begin && h.end >= g.end {
+				begin = h.begin // start of the Bind generator group
+				break
+			}
+		}
+
+		if s.redrawBindGroup(deadline, begin, g) {
+			i--
+		}
+	}
+}
+
+func (s *shrinker) redrawBindGroup(deadline time.Time, begin int, g groupInfo) bool {
+	for i := begin; i < g.end; i++ {
+		for u := s.rec.data[i] >> 1; s.rec.data[i] != 0 && time.Now().Before(deadline); u >>= 1 {
+			for n := 0; n < redrawBindTries; n++ {
+				if s.redraws >= maxBindRedraws {
+					return false
+				}
+				s.redraws++
+
+				buf := append([]uint64(nil), s.rec.data...)
+				buf[i] = u
+				for j := max(i+1, g.begin); j < g.end; j++ {
+					buf[j] = s.rng.rand()
+				}
+
+				if s.accept(buf, labelRedrawBind, "lower block %v to %v and re-draw group %q: [%v, %v)", i, u, g.label, g.begin, g.end) {
+					return true
+				}
+			}
+			if u == 0 {
+				break
+			}
+		}
+	}
+
+	return false
+}
+
 func (s *shrinker) accept(buf []uint64, label string, format string, args ...any) bool {
 	if compareData(buf, s.rec.data) >= 0 {
 		return false
@@ -258,8 +388,11 @@ func (s *shrinker) accept(buf []uint64, label string, format string, args ...any
 	s.debugf(true, label+": trying to reproduce the failure with a smaller test case: "+format, args...)
 	s.tries[label]++
 	s1 := newBufBitStream(buf, false)
-	err1 := checkOnce(newT(s.tb, s1, flags.debug && flags.verbose, nil), s.prop)
+	t1 := newT(s.tb, s1, flags.debug && flags.verbose, nil)
+	t1.cfg = s.cfg
+	err1 := checkOnce(t1, s.prop)
 	if traceback(err1) != traceback(s.err) {
+		s.pivot(buf, err1)
 		s.cache[bufStr] = struct{}{}
 		return false
 	}
@@ -268,7 +401,9 @@ func (s *shrinker) accept(buf []uint64, label string, format string, args ...any
 	s.tries[label]++
 	s.err = err1
 	s2 := newBufBitStream(buf, true)
-	err2 := checkOnce(newT(s.tb, s2, flags.debug && flags.verbose, nil), s.prop)
+	t2 := newT(s.tb, s2, flags.debug && flags.verbose, nil)
+	t2.cfg = s.cfg
+	err2 := checkOnce(t2, s.prop)
 	s.rec = s2.recordedBits
 	s.rec.prune()
 	assert(compareData(s.rec.data, buf) <= 0)
@@ -285,6 +420,21 @@ func (s *shrinker) accept(buf []uint64, label string, format string, args ...any
 	return true
 }
 
+func (s *shrinker) pivot(buf []uint64, err *testError) {
+	if err == nil || err.isInvalidData() || len(s.seen) >= maxDistinctErrors {
+		return
+	}
+
+	tb := traceback(err)
+	if _, ok := s.seen[tb]; ok {
+		return
+	}
+
+	s.debugf(false, "found distinct error %q", err)
+	s.seen[tb] = struct{}{}
+	s.found = append(s.found, failure{buf: buf, err1: err})
+}
+
 func minimize(u uint64, cond func(uint64, string) bool) uint64 {
 	if u == 0 {
 		return 0
@@ -9,10 +9,14 @@ package rapid
 import (
 	"fmt"
 	"math"
+	"math/big"
 	"math/bits"
+	"net/netip"
 	"sort"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 const shrinkTestRuns = 10
@@ -80,6 +84,141 @@ func TestShrink_FloatCmp(t *testing.T) {
 	}
 }
 
+func TestShrink_Duration(t *testing.T) {
+	t.Parallel()
+
+	checkShrink(t, func(t *T) {
+		d := Duration().Draw(t, "d")
+		if d < -time.Millisecond {
+			t.Fail()
+		}
+	}, -time.Millisecond-1)
+}
+
+func TestShrink_Time(t *testing.T) {
+	t.Parallel()
+
+	checkShrink(t, func(t *T) {
+		tm := Time().Draw(t, "t")
+		if tm.Year() >= 2000 {
+			t.Fail()
+		}
+	}, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+func TestShrink_BigInt(t *testing.T) {
+	t.Parallel()
+
+	limit := new(big.Int).Lsh(big.NewInt(1), 100)
+	checkShrink(t, func(t *T) {
+		i := BigInt().Draw(t, "i")
+		if i.CmpAbs(limit) > 0 {
+			t.Fail()
+		}
+	}, new(big.Int).Add(limit, big.NewInt(1)))
+}
+
+func TestShrink_Complex(t *testing.T) {
+	t.Parallel()
+
+	checkShrink(t, func(t *T) {
+		z := Complex128Range(-100-100i, 100+100i).Draw(t, "z")
+		if real(z) >= 1 && imag(z) <= -1 {
+			t.Fail()
+		}
+	}, complex(1, -1))
+}
+
+func TestShrink_Addr(t *testing.T) {
+	t.Parallel()
+
+	checkShrink(t, func(t *T) {
+		a := Addr().Draw(t, "a")
+		if a.Is4() && a.As4()[3] >= 10 {
+			t.Fail()
+		}
+	}, netip.AddrFrom4([4]byte{0, 0, 0, 10}))
+}
+
+func TestShrink_Frequency(t *testing.T) {
+	t.Parallel()
+
+	g := Frequency(
+		Weighted[int]{Weight: 1, Gen: IntRange(100, 200)},
+		Weighted[int]{Weight: 1000, Gen: IntRange(0, 10)},
+	)
+
+	checkShrink(t, func(t *T) {
+		g.Draw(t, "n")
+		t.Fail()
+	}, 100)
+}
+
+func TestShrink_Bind(t *testing.T) {
+	t.Parallel()
+
+	g := Bind(IntRange(1, 100), func(n int) *Generator[[]int] {
+		return SliceOfN(IntRange(0, n), n, n)
+	})
+
+	checkShrink(t, func(t *T) {
+		s := g.Draw(t, "s")
+		if len(s) >= 3 && s[0] >= 2 {
+			t.Fail()
+		}
+	}, []int{2, 0, 0})
+}
+
+func TestShrink_Zip2(t *testing.T) {
+	t.Parallel()
+
+	checkShrink(t, func(t *T) {
+		p := Zip2(Int(), Int()).Draw(t, "p")
+		if p.First >= 10 && p.Second > 0 {
+			t.Fail()
+		}
+	}, Tuple2[int, int]{10, 1})
+}
+
+func TestShrink_Recursive(t *testing.T) {
+	t.Parallel()
+
+	g := Recursive(Just("x"), func(child *Generator[string]) *Generator[string] {
+		return Map(SliceOfN(child, 1, 2), func(s []string) string { return "(" + strings.Join(s, " ") + ")" })
+	}, 16)
+
+	checkShrink(t, func(t *T) {
+		s := g.Draw(t, "s")
+		if strings.Contains(s, "((") {
+			t.Fail()
+		}
+	}, "((x))")
+}
+
+func TestShrink_SubsetOf(t *testing.T) {
+	t.Parallel()
+
+	checkShrink(t, func(t *T) {
+		s := SubsetOf([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}).Draw(t, "s")
+		for _, i := range s {
+			if i >= 5 {
+				t.Fail()
+			}
+		}
+	}, []int{5})
+}
+
+func TestShrink_SubmapOf(t *testing.T) {
+	t.Parallel()
+
+	checkShrink(t, func(t *T) {
+		m := SubmapOf(map[int]bool{1: true, 2: false, 3: true, 4: false, 5: true}).Draw(t, "m")
+		if len(m) >= 2 {
+			t.Fail()
+		}
+	}, map[int]bool{1: true, 2: false})
+}
+
 func TestShrink_IntSliceNElemsGt(t *testing.T) {
 	t.Parallel()
 
@@ -162,6 +301,44 @@ func TestShrink_StringOf(t *testing.T) {
 	}, "X", "")
 }
 
+func TestShrink_DistinctErrors(t *testing.T) {
+	t.Parallel()
+
+	prop := func(t *T) {
+		n := IntRange(0, 1000000).Draw(t, "n")
+		if n == 3 {
+			t.Fatalf("got three")
+		}
+		if n >= 1000 {
+			t.Fatalf("got big number %v", n)
+		}
+	}
+
+	cfg := flags
+	for seed := baseSeed(); ; seed++ {
+		s := newRandomBitStream(seed, true)
+		err := checkOnce(newT(t, s, false, nil), prop)
+		if err == nil || err.Error() == "got three" {
+			continue
+		}
+
+		failures := shrink(t, checkDeadline(nil), &cfg, s.recordedBits, err, prop, map[string]struct{}{})
+		if len(failures) != 2 {
+			t.Fatalf("got %v distinct failures instead of 2 (seed %v)", len(failures), seed)
+		}
+		for i, n := range []int{1000, 3} {
+			f := failures[i]
+			if traceback(f.err1) != traceback(f.err2) {
+				t.Fatalf("failure %v is flaky: %v vs %v", i, f.err1, f.err2)
+			}
+			nt := newT(t, newBufBitStream(f.buf, false), false, nil, n)
+			_ = checkOnce(nt, prop)
+		}
+
+		return
+	}
+}
+
 func TestMinimize_UnsetBits(t *testing.T) {
 	t.Parallel()
 
@@ -210,15 +387,19 @@ func checkShrink(t *testing.T, prop func(*T), draws ...any) {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			t.Helper()
 
-			_, _, _, seed, _, buf, err1, err2 := doCheck(t, checkDeadline(nil), 100, baseSeed(), "", false, prop)
-			if seed != 0 && err1 == nil && err2 == nil {
-				t.Fatalf("shrink test did not fail (seed %v)", seed)
+			cfg := flags
+			cfg.checks = 1000 // checking stops on the first failure
+			cfg.failfile = ""
+			_, _, _, failures := doCheck(t, checkDeadline(nil), &cfg, baseSeed(), nil, nil, prop)
+			if len(failures) == 0 {
+				t.Fatalf("shrink test did not fail")
 			}
-			if traceback(err1) != traceback(err2) {
-				t.Fatalf("flaky shrink test (seed %v)\nTraceback (%v):\n%vOriginal traceback (%v):\n%v", seed, err2, traceback(err2), err1, traceback(err1))
+			f := failures[0]
+			if traceback(f.err1) != traceback(f.err2) {
+				t.Fatalf("flaky shrink test (seed %v)\nTraceback (%v):\n%vOriginal traceback (%v):\n%v", f.seed, f.err2, traceback(f.err2), f.err1, traceback(f.err1))
 			}
 
-			nt := newT(t, newBufBitStream(buf, false), false, nil, draws...)
+			nt := newT(t, newBufBitStream(f.buf, false), false, nil, draws...)
 			_ = checkOnce(nt, prop)
 			if nt.draws != len(draws) {
 				t.Fatalf("different number of draws: %v vs expected %v", nt.draws, len(draws))
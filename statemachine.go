@@ -7,8 +7,12 @@
 package rapid
 
 import (
+	"fmt"
+	"math"
 	"reflect"
 	"sort"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -26,9 +30,46 @@ const (
 // For complex state machines, it can be more convenient to specify actions as
 // methods of a special state machine type. In this case, [StateMachineActions]
 // can be used to create an actions map from state machine methods using reflection.
+//
+// Failing sequences of actions are minimized by removing single actions, runs of
+// consecutive actions, and pairs of actions (like an action and a later one which
+// depends on it). On failure, the minimized sequence of executed actions is printed
+// as a numbered listing, together with the values drawn by each action.
 func (t *T) Repeat(actions map[string]func(*T)) {
 	t.Helper()
 
+	t.repeat(actions, nil, -1, -1)
+}
+
+// RepeatN is like [T.Repeat], but executes at least minSteps actions if minSteps >= 0,
+// and at most maxSteps actions if maxSteps >= 0. On average, RepeatN executes
+// as many actions beyond minSteps as [T.Repeat] does, limited by maxSteps.
+// RepeatN panics if maxSteps >= 0 and minSteps > maxSteps.
+func (t *T) RepeatN(actions map[string]func(*T), minSteps int, maxSteps int) {
+	t.Helper()
+	assertValidRange(minSteps, maxSteps)
+
+	t.repeat(actions, nil, minSteps, maxSteps)
+}
+
+// RepeatWeighted is like [T.Repeat], but executes each action with probability
+// proportional to its weight. Actions without a weight have a weight of 1.
+// RepeatWeighted panics if any of the weights is not positive, or if
+// there is a weight for an action which does not exist.
+func (t *T) RepeatWeighted(actions map[string]func(*T), weights map[string]int) {
+	t.Helper()
+
+	for key := range weights {
+		_, ok := actions[key]
+		assertf(ok && key != "", "weight for unknown action %q", key)
+	}
+
+	t.repeat(actions, weights, -1, -1)
+}
+
+func (t *T) repeat(actions map[string]func(*T), weights map[string]int, minSteps int, maxSteps int) {
+	t.Helper()
+
 	check := func(*T) {}
 	actionKeys := make([]string, 0, len(actions))
 	for key, action := range actions {
@@ -43,29 +84,188 @@ func (t *T) Repeat(actions map[string]func(*T)) {
 	}
 	sort.Strings(actionKeys)
 
-	steps := flags.steps
-	if testing.Short() {
-		steps /= 2
-	}
-
-	repeat := newRepeat(-1, -1, float64(steps), "Repeat")
+	repeat := t.newStepRepeat(minSteps, maxSteps, "Repeat")
 	sm := stateMachine{
 		check:      check,
 		actionKeys: SampledFrom(actionKeys),
 		actions:    actions,
 	}
+	if weights != nil {
+		w := make([]int, len(actionKeys))
+		for i, key := range actionKeys {
+			w[i] = 1
+			if weight, ok := weights[key]; ok {
+				w[i] = weight
+			}
+		}
+		sm.actionKeys = SampledFromWeighted(actionKeys, w)
+	}
 
-	sm.check(t)
-	t.failOnError()
-	for repeat.more(t.s) {
-		ok := sm.executeAction(t)
-		if ok {
-			sm.check(t)
-			t.failOnError()
-		} else {
-			repeat.reject()
+	t.recordActions(func() {
+		t.Helper()
+
+		sm.check(t)
+		t.failOnError()
+		if t.replay {
+			for t.draws < len(t.pinned) {
+				if sm.executeAction(t) {
+					sm.check(t)
+					t.failOnError()
+				}
+			}
+			return
+		}
+		for repeat.more(t.s) {
+			ok := sm.executeAction(t)
+			if ok {
+				sm.check(t)
+				t.failOnError()
+			} else {
+				repeat.reject()
+			}
 		}
+	})
+}
+
+// Command is a kind of command of a state machine test with a model of type M
+// and a system under test of type S, see [RepeatCommands].
+type Command[M any, S any] struct {
+	// Precondition, if not nil, reports whether the command can be executed in the model state m.
+	// Only the commands enabled in the current model state are drawn.
+	Precondition func(m M) bool
+	// Draw draws the inputs of the command, and returns the command call with these inputs.
+	Draw func(t *T, m M) CommandCall[M, S]
+}
+
+// CommandCall is a [Command] with its inputs drawn.
+type CommandCall[M any, S any] struct {
+	// Run executes the command on the system under test and returns its result.
+	Run func(t *T, s S) any
+	// Postcondition, if not nil, checks the result of the command,
+	// given the model state m before the command.
+	Postcondition func(t *T, m M, result any)
+	// NextState, if not nil, returns the model state after the command.
+	NextState func(m M, result any) M
+}
+
+// RepeatCommands executes a random sequence of commands (often called a "state machine" test)
+// on the system under test s, keeping track of the expected state of the system in the model m.
+// Only the commands with preconditions satisfied by the current model state are drawn.
+// For each command call, Run is executed first, then Postcondition, then NextState.
+// RepeatCommands stops early if none of the commands are enabled.
+// On failure, the executed commands are printed along with their inputs and results.
+func RepeatCommands[M any, S any](t *T, m M, s S, commands map[string]Command[M, S]) {
+	t.Helper()
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return
+	}
+	sort.Strings(names)
+
+	cm := commandMachine[M, S]{
+		names:    names,
+		commands: commands,
+		model:    m,
+		sut:      s,
+	}
+
+	t.recordActions(func() {
+		t.Helper()
+
+		t.history.state(cm.model)
+		if t.replay {
+			for t.draws < len(t.pinned) {
+				if _, ok := cm.executeCommand(t); !ok {
+					return
+				}
+			}
+			return
+		}
+		repeat := t.newStepRepeat(-1, -1, "RepeatCommands")
+		for repeat.more(t.s) {
+			valid, ok := cm.executeCommand(t)
+			if !ok {
+				repeat.forceStop = true
+			}
+			if !valid || !ok {
+				repeat.reject()
+			}
+		}
+	})
+}
+
+// newStepRepeat creates a repeat for the steps of a state machine, which executes on average
+// as many steps beyond minSteps as specified by -rapid.steps, limited by maxSteps
+// and by the maximum number of steps from [Settings].
+func (t *T) newStepRepeat(minSteps int, maxSteps int, label string) *repeat {
+	if t.cfg.maxSteps > 0 && (maxSteps < 0 || maxSteps > t.cfg.maxSteps) {
+		maxSteps = t.cfg.maxSteps
+		minSteps = min(minSteps, maxSteps)
+	}
+
+	steps := t.cfg.steps
+	if testing.Short() {
+		steps /= 2
 	}
+	avgSteps := float64(steps)
+	if minSteps > 0 {
+		avgSteps += float64(minSteps)
+	}
+	if maxSteps >= 0 {
+		avgSteps = math.Min(avgSteps, float64(max(minSteps, 0)+maxSteps)/2)
+	}
+
+	return newRepeat(minSteps, maxSteps, avgSteps, label)
+}
+
+type commandMachine[M any, S any] struct {
+	names    []string
+	commands map[string]Command[M, S]
+	model    M
+	sut      S
+}
+
+// executeCommand returns ok = false if none of the commands are enabled.
+func (cm *commandMachine[M, S]) executeCommand(t *T) (valid bool, ok bool) {
+	t.Helper()
+
+	enabled := make([]string, 0, len(cm.names))
+	for _, name := range cm.names {
+		if pre := cm.commands[name].Precondition; pre == nil || pre(cm.model) {
+			enabled = append(enabled, name)
+		}
+	}
+	if len(enabled) == 0 {
+		return false, false
+	}
+
+	i := t.s.beginGroup(actionLabel, false)
+	name := SampledFrom(enabled).Draw(t, "command")
+	t.history.begin(name)
+	invalid, _ := runAction(t, func(t *T) {
+		t.Helper()
+
+		call := cm.commands[name].Draw(t, cm.model)
+		result := call.Run(t, cm.sut)
+		t.history.result(result)
+		t.failOnError()
+		if call.Postcondition != nil {
+			call.Postcondition(t, cm.model, result)
+			t.failOnError()
+		}
+		if call.NextState != nil {
+			cm.model = call.NextState(cm.model, result)
+		}
+		t.history.state(cm.model)
+	})
+	t.history.end(invalid)
+	t.s.endGroup(i, t.replay) // pinned values do not use the bitstream
+
+	return !invalid, true
 }
 
 type StateMachine interface {
@@ -77,8 +277,10 @@ type StateMachine interface {
 	Check(*T)
 }
 
-// StateMachineActions creates an actions map for [*T.Repeat]
+// StateMachineActions creates an actions map for [*T.Repeat] (or [*T.RepeatWeighted])
 // from methods of a [StateMachine] type instance using reflection.
+// If the state machine implements [fmt.Stringer], its state after every action
+// is included in the action history printed on failure.
 func StateMachineActions(sm StateMachine) map[string]func(*T) {
 	var (
 		v = reflect.ValueOf(sm)
@@ -108,7 +310,10 @@ func StateMachineActions(sm StateMachine) map[string]func(*T) {
 	}
 
 	assertf(len(actions) > 0, "state machine of type %v has no actions specified", t)
-	actions[""] = sm.Check
+	actions[""] = func(t *T) {
+		sm.Check(t)
+		t.history.state(sm)
+	}
 
 	return actions
 }
@@ -124,9 +329,11 @@ func (sm *stateMachine) executeAction(t *T) bool {
 
 	for n := 0; n < validActionTries; n++ {
 		i := t.s.beginGroup(actionLabel, false)
-		action := sm.actions[sm.actionKeys.Draw(t, "action")]
-		invalid, skipped := runAction(t, action)
-		t.s.endGroup(i, false)
+		name := sm.actionKeys.Draw(t, "action")
+		t.history.begin(name)
+		invalid, skipped := runAction(t, sm.actions[name])
+		t.history.end(invalid)
+		t.s.endGroup(i, t.replay) // pinned values do not use the bitstream
 
 		if skipped {
 			continue
@@ -138,8 +345,128 @@ func (sm *stateMachine) executeAction(t *T) bool {
 	panic(stopTest(noValidActionsMsg))
 }
 
+// recordActions runs a state machine, and prints the history of its actions
+// if the state machine fails, when t is used to print the failing test case.
+func (t *T) recordActions(run func()) {
+	t.Helper()
+
+	if !t.shouldLog() {
+		run()
+		return
+	}
+
+	h := &actionHistory{}
+	defer func(prev *actionHistory) {
+		t.Helper()
+
+		t.history = prev
+		if !h.finished && len(h.steps) > 0 {
+			t.Logf("[rapid] action history:\n%v", h)
+		}
+	}(t.history)
+	t.history = h
+
+	run()
+	h.finished = true
+}
+
+// actionHistory is a listing of the actions executed by a state machine.
+// All methods are no-ops for a nil history.
+type actionHistory struct {
+	initial  string
+	steps    []actionStep
+	active   bool
+	finished bool
+}
+
+type actionStep struct {
+	name    string
+	draws   []string
+	result  string
+	state   string
+	skipped bool
+}
+
+func (h *actionHistory) begin(name string) {
+	if h == nil {
+		return
+	}
+
+	h.steps = append(h.steps, actionStep{name: name})
+	h.active = true
+}
+
+func (h *actionHistory) end(skipped bool) {
+	if h == nil || !h.active {
+		return
+	}
+
+	h.steps[len(h.steps)-1].skipped = skipped
+	h.active = false
+}
+
+func (h *actionHistory) draw(label string, v any) {
+	if h == nil || !h.active {
+		return
+	}
+
+	step := &h.steps[len(h.steps)-1]
+	step.draws = append(step.draws, fmt.Sprintf("%v=%#v", label, v))
+}
+
+func (h *actionHistory) result(v any) {
+	if h == nil || !h.active || v == nil {
+		return
+	}
+
+	h.steps[len(h.steps)-1].result = fmt.Sprintf("%#v", v)
+}
+
+func (h *actionHistory) state(v any) {
+	if h == nil {
+		return
+	}
+
+	s, ok := v.(fmt.Stringer)
+	if !ok {
+		return
+	}
+	if len(h.steps) == 0 {
+		h.initial = s.String()
+	} else {
+		h.steps[len(h.steps)-1].state = s.String()
+	}
+}
+
+func (h *actionHistory) String() string {
+	var b strings.Builder
+	width := len(strconv.Itoa(len(h.steps)))
+	if h.initial != "" {
+		fmt.Fprintf(&b, "%*v  state: %v\n", width, "", h.initial)
+	}
+	for i, step := range h.steps {
+		fmt.Fprintf(&b, "%*v. %v(%v)", width, i+1, step.name, strings.Join(step.draws, ", "))
+		if step.result != "" {
+			fmt.Fprintf(&b, " -> %v", step.result)
+		}
+		if step.skipped {
+			b.WriteString(" (skipped)")
+		}
+		b.WriteString("\n")
+		if step.state != "" {
+			fmt.Fprintf(&b, "%*v  state: %v\n", width, "", step.state)
+		}
+	}
+
+	return b.String()
+}
+
 func runAction(t *T, action func(*T)) (invalid bool, skipped bool) {
+	t.Helper()
+
 	defer func(draws int) {
+		t.Helper()
+
 		if r := recover(); r != nil {
 			if _, ok := r.(invalidData); ok {
 				invalid = true
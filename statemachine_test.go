@@ -7,7 +7,10 @@
 package rapid
 
 import (
+	"fmt"
+	"log"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -71,6 +74,252 @@ func TestStateMachine_Counter(t *testing.T) {
 	)
 }
 
+func TestStateMachine_Weighted(t *testing.T) {
+	t.Parallel()
+
+	var puts, resets int
+	Check(t, func(t *T) {
+		t.RepeatWeighted(map[string]func(*T){
+			"Put":   func(*T) { puts++ },
+			"Reset": func(*T) { resets++ },
+		}, map[string]int{"Put": 10})
+	})
+	if puts < 5*resets {
+		t.Fatalf("%v puts and %v resets", puts, resets)
+	}
+}
+
+func TestStateMachine_WeightedCounter(t *testing.T) {
+	t.Parallel()
+
+	checkShrink(t, func(t *T) {
+		var c buggyCounter
+		var n int
+		t.RepeatWeighted(map[string]func(*T){
+			"Inc":   func(*T) { c.Inc(); n++ },
+			"Dec":   func(*T) { c.Dec(); n-- },
+			"Reset": func(*T) { c.Reset(); n = 0 },
+			"": func(t *T) {
+				if c.Get() != n {
+					t.Fatalf("counter value is %v instead of %v", c.Get(), n)
+				}
+			},
+		}, map[string]int{"Inc": 5, "Dec": 5})
+	},
+		"Inc", "Inc", "Inc", "Inc",
+		"Dec",
+	)
+}
+
+func TestStateMachine_WeightedUnknown(t *testing.T) {
+	t.Parallel()
+
+	cfg := flags
+	cfg.failfile = ""
+	_, _, _, failures := doCheck(t, checkDeadline(nil), &cfg, baseSeed(), nil, nil, func(t *T) {
+		t.RepeatWeighted(map[string]func(*T){"Inc": func(*T) {}}, map[string]int{"Dec": 1})
+	})
+	if len(failures) == 0 {
+		t.Fatal("no failure for a weight of unknown action")
+	}
+	if msg := failures[0].err1.Error(); !strings.Contains(msg, "unknown action") {
+		t.Fatalf("unexpected error: %v", msg)
+	}
+}
+
+type counterMachine struct {
+	c buggyCounter
+	n int
+}
+
+func (sm *counterMachine) Check(t *T) {
+	if sm.c.Get() != sm.n {
+		t.Fatalf("counter value is %v instead of %v", sm.c.Get(), sm.n)
+	}
+}
+
+func (sm *counterMachine) Add(t *T) {
+	k := IntRange(1, 3).Draw(t, "k")
+	for i := 0; i < k; i++ {
+		sm.c.Inc()
+	}
+	sm.n += k
+}
+
+func (sm *counterMachine) Dec(t *T) {
+	if sm.n == 0 {
+		t.Skip("counter is zero")
+	}
+	sm.c.Dec()
+	sm.n--
+}
+
+func (sm *counterMachine) String() string {
+	return fmt.Sprintf("n=%v", sm.n)
+}
+
+func TestStateMachine_History(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+	rt := newT(nil, newBufBitStream(nil, false), false, log.New(&b, "", 0))
+	rt.pinned = []any{"Dec", "Add", 2, "Add", 3, "Dec"}
+	rt.replay = true
+	err := checkOnce(rt, func(t *T) {
+		t.Repeat(StateMachineActions(&counterMachine{}))
+	})
+	if err == nil {
+		t.Fatal("buggy counter has not failed")
+	}
+
+	expected := `[rapid] action history:
+   state: n=0
+1. Dec() (skipped)
+2. Add(k=2)
+   state: n=2
+3. Add(k=3)
+   state: n=5
+4. Dec()
+`
+	if !strings.Contains(b.String(), expected) {
+		t.Fatalf("got output\n%v\nwithout the history\n%v", b.String(), expected)
+	}
+}
+
+func counterCommand(op func(*buggyCounter), next func(int) int) CommandCall[int, *buggyCounter] {
+	return CommandCall[int, *buggyCounter]{
+		Run: func(_ *T, c *buggyCounter) any {
+			op(c)
+			return c.Get()
+		},
+		Postcondition: func(t *T, n int, result any) {
+			if result != next(n) {
+				t.Fatalf("counter value is %v instead of %v", result, next(n))
+			}
+		},
+		NextState: func(n int, _ any) int { return next(n) },
+	}
+}
+
+var counterCommands = map[string]Command[int, *buggyCounter]{
+	"Inc": {
+		Draw: func(*T, int) CommandCall[int, *buggyCounter] {
+			return counterCommand((*buggyCounter).Inc, func(n int) int { return n + 1 })
+		},
+	},
+	"Dec": {
+		Precondition: func(n int) bool { return n > 0 },
+		Draw: func(*T, int) CommandCall[int, *buggyCounter] {
+			return counterCommand((*buggyCounter).Dec, func(n int) int { return n - 1 })
+		},
+	},
+	"Reset": {
+		Draw: func(*T, int) CommandCall[int, *buggyCounter] {
+			return counterCommand((*buggyCounter).Reset, func(int) int { return 0 })
+		},
+	},
+}
+
+func TestRepeatCommands_Counter(t *testing.T) {
+	t.Parallel()
+
+	checkShrink(t, func(t *T) {
+		RepeatCommands(t, 0, &buggyCounter{}, counterCommands)
+	},
+		"Inc", "Inc", "Inc", "Inc",
+		"Dec",
+	)
+}
+
+func TestRepeatCommands_Preconditions(t *testing.T) {
+	t.Parallel()
+
+	Check(t, func(t *T) {
+		var n int
+		RepeatCommands(t, 0, &n, map[string]Command[int, *int]{
+			"Inc": {
+				Precondition: func(m int) bool { return m < 3 },
+				Draw: func(t *T, m int) CommandCall[int, *int] {
+					by := IntRange(1, 3-m).Draw(t, "by")
+					return CommandCall[int, *int]{
+						Run:       func(_ *T, n *int) any { *n += by; return *n },
+						NextState: func(m int, _ any) int { return m + by },
+						Postcondition: func(t *T, m int, result any) {
+							if result.(int) > 3 {
+								t.Fatalf("precondition violated: %v + %v", m, by)
+							}
+						},
+					}
+				},
+			},
+		})
+		if n > 3 {
+			t.Fatalf("counter value is %v", n)
+		}
+	})
+}
+
+func TestStateMachine_RepeatN(t *testing.T) {
+	t.Parallel()
+
+	for _, r := range [][2]int{{0, 0}, {5, 5}, {0, 10}, {20, -1}, {50, 100}} {
+		Check(t, func(t *T) {
+			n := 0
+			t.RepeatN(map[string]func(*T){
+				"Inc": func(*T) { n++ },
+			}, r[0], r[1])
+			if n < r[0] || (r[1] >= 0 && n > r[1]) {
+				t.Fatalf("%v actions executed instead of [%v, %v]", n, r[0], r[1])
+			}
+		})
+	}
+}
+
+func TestStateMachine_MaxSteps(t *testing.T) {
+	t.Parallel()
+
+	longest := 0
+	CheckWith(t, Settings{Steps: 100, MaxSteps: 5}, func(t *T) {
+		n := 0
+		t.RepeatN(map[string]func(*T){
+			"Inc": func(*T) { n++ },
+		}, 10, -1)
+		if n > 5 {
+			t.Fatalf("%v actions executed instead of at most 5", n)
+		}
+		longest = max(longest, n)
+	})
+	if longest != 5 {
+		t.Fatalf("got at most %v actions instead of 5", longest)
+	}
+}
+
+func TestStateMachine_DependentSteps(t *testing.T) {
+	t.Parallel()
+
+	checkShrink(t, func(t *T) {
+		var open, marks int
+		t.RepeatN(map[string]func(*T){
+			"Acquire": func(*T) { open++ },
+			"Release": func(*T) { open-- },
+			"Tick":    func(*T) { marks++ },
+			"": func(t *T) {
+				if open < 0 {
+					t.SkipNow()
+				}
+			},
+		}, 0, 10)
+		if open != 0 {
+			t.SkipNow()
+		}
+		if marks >= 2 {
+			t.Fatalf("marked %v times", marks)
+		}
+	},
+		"Tick", "Tick",
+	)
+}
+
 func TestStateMachine_Halting(t *testing.T) {
 	t.Parallel()
 
@@ -296,7 +545,10 @@ func TestStateMachineActions(t *testing.T) {
 }
 
 func BenchmarkCheckQueue(b *testing.B) {
+	cfg := flags
+	cfg.checks = 100
+	cfg.failfile = ""
 	for i := 0; i < b.N; i++ {
-		_, _, _, _, _, _, _, _ = doCheck(b, checkDeadline(nil), 100, baseSeed(), "", false, queueTest)
+		_, _, _, _ = doCheck(b, checkDeadline(nil), &cfg, baseSeed(), nil, nil, queueTest)
 	}
 }
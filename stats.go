@@ -0,0 +1,286 @@
+// Copyright 2019 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rapid
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Stats contains statistics collected with [T.Event] and [T.Label]
+// during a single [Check] run. Only valid test cases are taken into account,
+// excluding the ones produced by mutation for [T.Target].
+type Stats struct {
+	// Valid is the number of valid test cases.
+	Valid int
+	// Events maps each event to the number of test cases it has happened in.
+	Events map[string]int
+	// Labels maps each label key and value to the number of test cases
+	// the value has been recorded in.
+	Labels map[string]map[string]int
+	// Cover maps each [T.Cover] label to the number of test cases
+	// the condition has been true in.
+	Cover map[string]int
+	// Targets maps each [T.Target] label to the best score found.
+	Targets map[string]float64
+}
+
+// Event records that the named event has happened in the current test case.
+// The fraction of valid test cases each event has happened in is reported
+// when -rapid.stats or -rapid.v flag is specified, and is available
+// programmatically using [Settings.OnStats].
+//
+// Recording the same event several times in one test case has the same
+// effect as recording it once.
+func (t *T) Event(name string) {
+	if t.stats != nil {
+		t.stats.event(name)
+	}
+}
+
+// Label records value for the label key in the current test case.
+// Labels are reported like [T.Event], with values of the same key grouped together.
+// Several different values can be recorded for one key in a single test case.
+func (t *T) Label(key string, value string) {
+	if t.stats != nil {
+		t.stats.label(key, value)
+	}
+}
+
+// Cover requires cond to be true in at least pct percent (from 0 to 100)
+// of valid test cases, with label identifying the requirement.
+// After all test cases have been run, [Check] fails if the requirement is not met,
+// guarding against generators which silently stop producing interesting values.
+//
+// By default, the observed percentage is compared with the required one exactly,
+// so a requirement close to the actual probability of cond fails randomly.
+// With [Settings.CheckCoverage], rapid keeps generating test cases (up to
+// a limit) until it is statistically confident whether the requirement is met,
+// and fails only if it is confident that it is not.
+func (t *T) Cover(pct float64, cond bool, label string) {
+	assertf(pct >= 0 && pct <= 100, "invalid coverage percentage %v", pct)
+
+	if t.stats != nil {
+		t.stats.cover(pct, cond, label)
+	}
+}
+
+type caseStats struct {
+	mu      sync.Mutex
+	events  map[string]struct{}
+	labels  map[string]map[string]struct{}
+	covered map[string]coverage
+	targets map[string]float64
+}
+
+type coverage struct {
+	pct  float64
+	hits int
+}
+
+func (c *caseStats) event(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.events == nil {
+		c.events = map[string]struct{}{}
+	}
+	c.events[name] = struct{}{}
+}
+
+func (c *caseStats) label(key string, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.labels == nil {
+		c.labels = map[string]map[string]struct{}{}
+	}
+	if c.labels[key] == nil {
+		c.labels[key] = map[string]struct{}{}
+	}
+	c.labels[key][value] = struct{}{}
+}
+
+func (c *caseStats) cover(pct float64, cond bool, label string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.covered == nil {
+		c.covered = map[string]coverage{}
+	}
+	cov := c.covered[label]
+	cov.pct = math.Max(cov.pct, pct)
+	if cond {
+		cov.hits = 1
+	}
+	c.covered[label] = cov
+}
+
+type checkStats struct {
+	valid   int
+	events  map[string]int
+	labels  map[string]map[string]int
+	covered map[string]coverage
+	targets map[string]*target
+}
+
+func newCheckStats() *checkStats {
+	return &checkStats{
+		events:  map[string]int{},
+		labels:  map[string]map[string]int{},
+		covered: map[string]coverage{},
+		targets: map[string]*target{},
+	}
+}
+
+func (s *checkStats) add(c *caseStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s.valid++
+	for name := range c.events {
+		s.events[name]++
+	}
+	for key, values := range c.labels {
+		if s.labels[key] == nil {
+			s.labels[key] = map[string]int{}
+		}
+		for value := range values {
+			s.labels[key][value]++
+		}
+	}
+	for label, cc := range c.covered {
+		cov := s.covered[label]
+		cov.pct = math.Max(cov.pct, cc.pct)
+		cov.hits += cc.hits
+		s.covered[label] = cov
+	}
+}
+
+// coverageUndecided reports whether there is a coverage requirement which
+// we can not yet say with confidence is either met or not.
+func (s *checkStats) coverageUndecided() bool {
+	for _, cov := range s.covered {
+		lo, hi := wilsonInterval(cov.hits, s.valid, coverageZ)
+		if lo < cov.pct/100 && hi >= cov.pct/100 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *checkStats) insufficientCoverage(statistical bool) []string {
+	var msgs []string
+	for _, label := range sortedKeys(s.covered) {
+		cov := s.covered[label]
+		_, hi := wilsonInterval(cov.hits, s.valid, coverageZ)
+		observed := 100 * float64(cov.hits) / float64(s.valid)
+		switch {
+		case s.valid == 0:
+			continue
+		case statistical && hi >= cov.pct/100:
+			continue // not confidently insufficient
+		case !statistical && observed >= cov.pct:
+			continue
+		}
+		msgs = append(msgs, fmt.Sprintf("%q covered in %.2f%% of %v valid tests, %v%% required", label, observed, s.valid, cov.pct))
+	}
+
+	return msgs
+}
+
+// wilsonInterval returns the Wilson score interval for the probability of success,
+// given the number of successes out of n trials.
+func wilsonInterval(successes int, n int, z float64) (float64, float64) {
+	if n == 0 {
+		return 0, 1
+	}
+
+	p := float64(successes) / float64(n)
+	nf := float64(n)
+	d := 1 + z*z/nf
+	center := (p + z*z/(2*nf)) / d
+	half := z / d * math.Sqrt(p*(1-p)/nf+z*z/(4*nf*nf))
+
+	return center - half, center + half
+}
+
+func (s *checkStats) export() Stats {
+	st := Stats{
+		Valid:   s.valid,
+		Events:  make(map[string]int, len(s.events)),
+		Labels:  make(map[string]map[string]int, len(s.labels)),
+		Cover:   make(map[string]int, len(s.covered)),
+		Targets: make(map[string]float64, len(s.targets)),
+	}
+	for name, n := range s.events {
+		st.Events[name] = n
+	}
+	for key, values := range s.labels {
+		st.Labels[key] = make(map[string]int, len(values))
+		for value, n := range values {
+			st.Labels[key][value] = n
+		}
+	}
+	for label, cov := range s.covered {
+		st.Cover[label] = cov.hits
+	}
+	for label, tgt := range s.targets {
+		st.Targets[label] = tgt.score
+	}
+
+	return st
+}
+
+func (s *checkStats) empty() bool {
+	return len(s.events) == 0 && len(s.labels) == 0 && len(s.covered) == 0
+}
+
+func (s *checkStats) String() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "[rapid] statistics for %v valid tests:", s.valid)
+	if len(s.events) > 0 {
+		b.WriteString("\n  events:")
+		writeCounts(b, s.events, s.valid)
+	}
+	for _, key := range sortedKeys(s.labels) {
+		fmt.Fprintf(b, "\n  %v:", key)
+		writeCounts(b, s.labels[key], s.valid)
+	}
+	if len(s.covered) > 0 {
+		b.WriteString("\n  coverage:")
+		for _, label := range sortedKeys(s.covered) {
+			cov := s.covered[label]
+			fmt.Fprintf(b, "\n    %6.2f%% %v (%v, %v%% required)", 100*float64(cov.hits)/float64(s.valid), label, cov.hits, cov.pct)
+		}
+	}
+
+	return b.String()
+}
+
+func writeCounts(b *strings.Builder, counts map[string]int, total int) {
+	names := sortedKeys(counts)
+	sort.SliceStable(names, func(i, j int) bool { return counts[names[i]] > counts[names[j]] })
+
+	for _, name := range names {
+		fmt.Fprintf(b, "\n    %6.2f%% %v (%v)", 100*float64(counts[name])/float64(total), name, counts[name])
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,158 @@
+// Copyright 2019 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rapid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	var st Stats
+	CheckWith(t, Settings{OnStats: func(s Stats) { st = s }}, func(t *T) {
+		n := IntRange(0, 9).Draw(t, "n")
+		if n == 0 {
+			t.Skip("zero")
+		}
+		t.Event("valid")
+		t.Event("valid")
+		if n%2 == 0 {
+			t.Label("parity", "even")
+		} else {
+			t.Label("parity", "odd")
+		}
+		t.Label("size", "any")
+		if n > 5 {
+			t.Label("size", "big")
+		}
+	})
+
+	if st.Valid == 0 {
+		t.Fatalf("no valid test cases recorded")
+	}
+	if st.Events["valid"] != st.Valid {
+		t.Errorf("got %v valid events for %v valid test cases", st.Events["valid"], st.Valid)
+	}
+	if st.Labels["parity"]["even"]+st.Labels["parity"]["odd"] != st.Valid {
+		t.Errorf("parity labels %v do not add up to %v valid test cases", st.Labels["parity"], st.Valid)
+	}
+	if st.Labels["size"]["any"] != st.Valid || st.Labels["size"]["big"] > st.Valid {
+		t.Errorf("unexpected size labels %v for %v valid test cases", st.Labels["size"], st.Valid)
+	}
+}
+
+func TestStats_Custom(t *testing.T) {
+	t.Parallel()
+
+	gen := Custom(func(t *T) int {
+		t.Event("custom")
+		return Int().Draw(t, "n")
+	})
+
+	var st Stats
+	CheckWith(t, Settings{OnStats: func(s Stats) { st = s }}, func(t *T) {
+		gen.Draw(t, "n")
+	})
+
+	if st.Events["custom"] != st.Valid {
+		t.Errorf("got %v custom events for %v valid test cases", st.Events["custom"], st.Valid)
+	}
+}
+
+func TestCheckStatsString(t *testing.T) {
+	t.Parallel()
+
+	st := newCheckStats()
+	for i := 0; i < 4; i++ {
+		c := &caseStats{}
+		if i < 3 {
+			c.event("b")
+		}
+		c.event("a")
+		c.label("k", "v")
+		st.add(c)
+	}
+
+	want := []string{
+		"[rapid] statistics for 4 valid tests:",
+		"  events:",
+		"    100.00% a (4)",
+		"     75.00% b (3)",
+		"  k:",
+		"    100.00% v (4)",
+	}
+	if s := st.String(); s != strings.Join(want, "\n") {
+		t.Errorf("got\n%v\ninstead of\n%v", s, strings.Join(want, "\n"))
+	}
+}
+
+func TestCover(t *testing.T) {
+	t.Parallel()
+
+	var st Stats
+	CheckWith(t, Settings{OnStats: func(s Stats) { st = s }}, func(t *T) {
+		n := IntRange(0, 9).Draw(t, "n")
+		t.Cover(0, n == 0, "zero")
+		t.Cover(1, true, "any")
+	})
+
+	if st.Cover["any"] != st.Valid {
+		t.Errorf("got %v covered test cases instead of %v", st.Cover["any"], st.Valid)
+	}
+}
+
+func TestCheckCoverage(t *testing.T) {
+	t.Parallel()
+
+	var st Stats
+	CheckWith(t, Settings{Checks: 10, CheckCoverage: true, OnStats: func(s Stats) { st = s }}, func(t *T) {
+		n := IntRange(0, 9).Draw(t, "n")
+		t.Cover(30, n < 5, "small")
+	})
+
+	if !flagSet("rapid.checks", "RAPID_CHECKS") && !flagSet("rapid.checkcoverage", "RAPID_CHECKCOVERAGE") && st.Valid <= 10 {
+		t.Errorf("got %v valid test cases, expected more to decide on coverage", st.Valid)
+	}
+}
+
+func TestInsufficientCoverage(t *testing.T) {
+	t.Parallel()
+
+	st := newCheckStats()
+	for i := 0; i < 100; i++ {
+		c := &caseStats{}
+		c.cover(10, i < 5, "rare")
+		c.cover(5, i < 8, "close")
+		st.add(c)
+	}
+
+	if msgs := st.insufficientCoverage(false); len(msgs) != 1 || !strings.Contains(msgs[0], `"rare"`) {
+		t.Errorf("unexpected coverage failures %q", msgs)
+	}
+	if msgs := st.insufficientCoverage(true); len(msgs) != 0 {
+		t.Errorf("unexpected confident coverage failures %q", msgs)
+	}
+	if !st.coverageUndecided() {
+		t.Errorf("coverage should not be decided after 100 tests")
+	}
+
+	for i := 0; i < 10000; i++ {
+		c := &caseStats{}
+		c.cover(10, i%20 == 0, "rare")
+		c.cover(5, true, "close")
+		st.add(c)
+	}
+
+	if msgs := st.insufficientCoverage(true); len(msgs) != 1 || !strings.Contains(msgs[0], `"rare"`) {
+		t.Errorf("unexpected confident coverage failures %q", msgs)
+	}
+	if st.coverageUndecided() {
+		t.Errorf("coverage should be decided after 10100 tests")
+	}
+}
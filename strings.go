@@ -8,10 +8,13 @@ package rapid
 
 import (
 	"bytes"
+	"cmp"
 	"fmt"
 	"math"
 	"regexp"
 	"regexp/syntax"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"unicode"
@@ -56,7 +59,6 @@ var (
 		unicode.Co, // Other, private use     (137468)
 	}
 
-	expandedTables  = sync.Map{} // *unicode.RangeTable / regexp name -> []rune
 	compiledRegexps = sync.Map{} // regexp -> compiledRegexp
 	regexpNames     = sync.Map{} // *regexp.Regexp -> string
 	charClassGens   = sync.Map{} // regexp name -> *Generator
@@ -89,24 +91,148 @@ func runesFrom(default_ bool, runes []rune, tables ...*unicode.RangeTable) *Gene
 		assertf(len(tables) > 0, "at least one *unicode.RangeTable should be specified")
 	}
 
+	return newRuneGen(RuneSet{}.Runes(runes...).Tables(tables...), default_)
+}
+
+// RuneSet is a set of runes for [RuneFromSet], built from individual runes, ranges
+// and [unicode.RangeTable] tables, with some runes possibly excluded.
+// The zero value is an empty set. RuneSet methods never modify the set,
+// returning an updated copy instead.
+type RuneSet struct {
+	runes   []rune
+	parts   [][]runeRange
+	exclude []runeRange
+}
+
+// RuneSetDefault returns the set of runes used by [Rune].
+func RuneSetDefault() RuneSet {
+	return RuneSet{}.Runes(defaultRunes...).Tables(defaultTables...)
+}
+
+// RuneSetNoControl returns the set of runes used by [Rune], without control characters ([unicode.Cc]).
+func RuneSetNoControl() RuneSet {
+	return RuneSetDefault().ExceptTables(unicode.Cc)
+}
+
+// RuneSetPrintableASCII returns the set of printable ASCII runes, from ' ' to '~'.
+func RuneSetPrintableASCII() RuneSet {
+	return RuneSet{}.Runes('A', 'a', '0', ' ').Range(' ', '~')
+}
+
+// RuneSetIdentStart returns the set of runes that can start a Go identifier: letters and '_'.
+func RuneSetIdentStart() RuneSet {
+	return RuneSet{}.Runes('a', 'A', '_').Tables(unicode.L)
+}
+
+// RuneSetIdentContinue returns the set of runes that can continue a Go identifier:
+// letters, decimal digits and '_'.
+func RuneSetIdentContinue() RuneSet {
+	return RuneSet{}.Runes('a', 'A', '_', '0').Tables(unicode.L, unicode.Nd)
+}
+
+// Runes returns a copy of the set with runes added. Generated runes shrink towards the
+// first ones added with Runes.
+func (rs RuneSet) Runes(runes ...rune) RuneSet {
+	rs.runes = append(slices.Clip(rs.runes), runes...)
+	return rs
+}
+
+// Range returns a copy of the set with runes in range [lo, hi] added.
+// Range panics if the range is invalid.
+func (rs RuneSet) Range(lo rune, hi rune) RuneSet {
+	assertf(lo >= 0 && lo <= hi && hi <= unicode.MaxRune, "invalid rune range [%q, %q]", lo, hi)
+
+	rs.parts = append(slices.Clip(rs.parts), []runeRange{{lo, hi, 1}})
+	return rs
+}
+
+// Tables returns a copy of the set with runes from tables added.
+// Tables panics if tables contain an empty table.
+func (rs RuneSet) Tables(tables ...*unicode.RangeTable) RuneSet {
+	rs.parts = slices.Clip(rs.parts)
+	for i, t := range tables {
+		ranges := tableRanges(t)
+		assertf(len(ranges) > 0, "empty *unicode.RangeTable %v", i)
+		rs.parts = append(rs.parts, ranges)
+	}
+
+	return rs
+}
+
+// Except returns a copy of the set with runes excluded.
+func (rs RuneSet) Except(runes ...rune) RuneSet {
+	rs.exclude = slices.Clip(rs.exclude)
+	for _, r := range runes {
+		rs.exclude = append(rs.exclude, runeRange{r, r, 1})
+	}
+
+	return rs
+}
+
+// ExceptRange returns a copy of the set with runes in range [lo, hi] excluded.
+// ExceptRange panics if the range is invalid.
+func (rs RuneSet) ExceptRange(lo rune, hi rune) RuneSet {
+	assertf(lo >= 0 && lo <= hi && hi <= unicode.MaxRune, "invalid rune range [%q, %q]", lo, hi)
+
+	rs.exclude = append(slices.Clip(rs.exclude), runeRange{lo, hi, 1})
+	return rs
+}
+
+// ExceptTables returns a copy of the set with runes from tables excluded.
+func (rs RuneSet) ExceptTables(tables ...*unicode.RangeTable) RuneSet {
+	rs.exclude = slices.Clip(rs.exclude)
+	for _, t := range tables {
+		rs.exclude = append(rs.exclude, tableRanges(t)...)
+	}
+
+	return rs
+}
+
+// RuneFromSet creates a rune generator from the provided set. Individual runes of the set
+// are chosen as often as all of its ranges and tables combined.
+// RuneFromSet panics if the set is empty.
+func RuneFromSet(rs RuneSet) *Generator[rune] {
+	return newRuneGen(rs, false)
+}
+
+func newRuneGen(rs RuneSet, default_ bool) *Generator[rune] {
+	exclude := normalizeRuneRanges(rs.exclude)
+
+	runes := rs.runes
+	if len(exclude) > 0 {
+		runes = slices.DeleteFunc(slices.Clone(runes), func(r rune) bool {
+			i := sort.Search(len(exclude), func(i int) bool { return exclude[i].hi >= r })
+			return i < len(exclude) && exclude[i].lo <= r
+		})
+	}
+
+	var tables []runeRanges
+	for _, part := range rs.parts {
+		if len(exclude) > 0 {
+			var ranges []runeRange
+			for _, r := range part {
+				ranges = r.without(exclude, ranges)
+			}
+			part = ranges
+		}
+		if len(part) > 0 {
+			tables = append(tables, newRuneRanges(part))
+		}
+	}
+	assertf(len(runes) > 0 || len(tables) > 0, "rune set should not be empty")
+
 	var weights []int
 	if len(runes) > 0 {
-		weights = append(weights, len(tables))
+		weights = append(weights, max(len(tables), 1))
 	}
 	for range tables {
 		weights = append(weights, 1)
 	}
 
-	tables_ := make([][]rune, len(tables))
-	for i := range tables {
-		tables_[i] = expandRangeTable(tables[i], tables[i])
-		assertf(len(tables_[i]) > 0, "empty *unicode.RangeTable %v", i)
-	}
-
 	return newGenerator[rune](&runeGen{
 		die:      newLoadedDie(weights),
 		runes:    runes,
-		tables:   tables_,
+		tables:   tables,
 		default_: default_,
 	})
 }
@@ -114,7 +240,7 @@ func runesFrom(default_ bool, runes []rune, tables ...*unicode.RangeTable) *Gene
 type runeGen struct {
 	die      *loadedDie
 	runes    []rune
-	tables   [][]rune
+	tables   []runeRanges
 	default_ bool
 }
 
@@ -129,14 +255,116 @@ func (g *runeGen) String() string {
 func (g *runeGen) value(t *T) rune {
 	n := g.die.roll(t.s)
 
-	runes := g.runes
 	if len(g.runes) == 0 {
-		runes = g.tables[n]
+		return g.tables[n].pick(t.s)
 	} else if n > 0 {
-		runes = g.tables[n-1]
+		return g.tables[n-1].pick(t.s)
+	}
+
+	return g.runes[genIndex(t.s, len(g.runes), true)]
+}
+
+// runeRange is a range of runes [lo, hi] with a stride, like [unicode.Range32].
+type runeRange struct {
+	lo     rune
+	hi     rune
+	stride rune
+}
+
+func (r runeRange) len() int {
+	return int((r.hi-r.lo)/r.stride) + 1
+}
+
+// without appends to out what remains of r after removing sorted non-overlapping unit-stride ranges.
+func (r runeRange) without(exclude []runeRange, out []runeRange) []runeRange {
+	i := sort.Search(len(exclude), func(i int) bool { return exclude[i].hi >= r.lo })
+	for ; i < len(exclude) && exclude[i].lo <= r.hi; i++ {
+		e := exclude[i]
+		if e.hi < r.lo {
+			continue // in the gap between the runes of a strided range
+		}
+		if e.lo > r.lo {
+			out = append(out, runeRange{r.lo, r.lo + (e.lo-1-r.lo)/r.stride*r.stride, r.stride})
+		}
+		next := r.lo + ((e.hi-r.lo)/r.stride+1)*r.stride
+		if next > r.hi {
+			return out
+		}
+		r.lo = next
+	}
+
+	return append(out, r)
+}
+
+// runeRanges is a sequence of rune ranges, indexed as if all the ranges were expanded
+// into a single []rune, without actually doing so.
+type runeRanges struct {
+	ranges []runeRange
+	ends   []int // ends[i] is the total length of ranges[:i+1]
+}
+
+func newRuneRanges(ranges []runeRange) runeRanges {
+	ends := make([]int, len(ranges))
+	n := 0
+	for i, r := range ranges {
+		n += r.len()
+		ends[i] = n
 	}
 
-	return runes[genIndex(t.s, len(runes), true)]
+	return runeRanges{
+		ranges: ranges,
+		ends:   ends,
+	}
+}
+
+func (rr runeRanges) pick(s bitStream) rune {
+	ix := genIndex(s, rr.ends[len(rr.ends)-1], true)
+
+	i := sort.Search(len(rr.ends), func(i int) bool { return rr.ends[i] > ix })
+	start := 0
+	if i > 0 {
+		start = rr.ends[i-1]
+	}
+
+	return rr.ranges[i].lo + rune(ix-start)*rr.ranges[i].stride
+}
+
+func tableRanges(t *unicode.RangeTable) []runeRange {
+	ranges := make([]runeRange, 0, len(t.R16)+len(t.R32))
+	for _, r := range t.R16 {
+		ranges = append(ranges, runeRange{rune(r.Lo), rune(r.Hi), rune(r.Stride)})
+	}
+	for _, r := range t.R32 {
+		ranges = append(ranges, runeRange{rune(r.Lo), rune(r.Hi), rune(r.Stride)})
+	}
+
+	return ranges
+}
+
+// normalizeRuneRanges converts ranges to sorted, non-overlapping unit-stride ones.
+func normalizeRuneRanges(ranges []runeRange) []runeRange {
+	var units []runeRange
+	for _, r := range ranges {
+		if r.stride == 1 {
+			units = append(units, r)
+			continue
+		}
+		for c := r.lo; c <= r.hi; c += r.stride {
+			units = append(units, runeRange{c, c, 1})
+		}
+	}
+	slices.SortFunc(units, func(a, b runeRange) int { return cmp.Compare(a.lo, b.lo) })
+
+	var norm []runeRange
+	for _, r := range units {
+		if n := len(norm); n > 0 && r.lo <= norm[n-1].hi+1 {
+			norm[n-1].hi = max(norm[n-1].hi, r.hi)
+		} else {
+			norm = append(norm, r)
+		}
+	}
+
+	return norm
 }
 
 // String is a shorthand for [StringOf]([Rune]()).
@@ -368,36 +596,6 @@ func maybeFoldCase(s bitStream, r rune, flags syntax.Flags) rune {
 	return r
 }
 
-func expandRangeTable(t *unicode.RangeTable, key any) []rune {
-	cached, ok := expandedTables.Load(key)
-	if ok {
-		return cached.([]rune)
-	}
-
-	n := 0
-	for _, r := range t.R16 {
-		n += int(r.Hi-r.Lo)/int(r.Stride) + 1
-	}
-	for _, r := range t.R32 {
-		n += int(r.Hi-r.Lo)/int(r.Stride) + 1
-	}
-
-	ret := make([]rune, 0, n)
-	for _, r := range t.R16 {
-		for i := uint32(r.Lo); i <= uint32(r.Hi); i += uint32(r.Stride) {
-			ret = append(ret, rune(i))
-		}
-	}
-	for _, r := range t.R32 {
-		for i := uint64(r.Lo); i <= uint64(r.Hi); i += uint64(r.Stride) {
-			ret = append(ret, rune(i))
-		}
-	}
-	expandedTables.Store(key, ret)
-
-	return ret
-}
-
 func compileRegexp(expr string) (compiledRegexp, error) {
 	cached, ok := compiledRegexps.Load(expr)
 	if ok {
@@ -438,20 +636,14 @@ func charClassGen(re *syntax.Regexp) *Generator[rune] {
 		return cached.(*Generator[rune])
 	}
 
-	t := &unicode.RangeTable{R32: make([]unicode.Range32, 0, len(re.Rune)/2)}
+	ranges := make([]runeRange, 0, len(re.Rune)/2)
 	for i := 0; i < len(re.Rune); i += 2 {
-		// not a valid unicode.Range32, since it requires that Lo and Hi must always be >= 1<<16
-		// however, we don't really care, since the only use of these ranges is as input to expandRangeTable
-		t.R32 = append(t.R32, unicode.Range32{
-			Lo:     uint32(re.Rune[i]),
-			Hi:     uint32(re.Rune[i+1]),
-			Stride: 1,
-		})
+		ranges = append(ranges, runeRange{re.Rune[i], re.Rune[i+1], 1})
 	}
 
 	g := newGenerator[rune](&runeGen{
 		die:    newLoadedDie([]int{1}),
-		tables: [][]rune{expandRangeTable(t, regexpName(re))},
+		tables: []runeRanges{newRuneRanges(ranges)},
 	})
 	charClassGens.Store(regexpName(re), g)
 
@@ -98,3 +98,89 @@ func TestStringNMaxLen(t *testing.T) {
 		}))
 	}
 }
+
+func TestRuneFromSet(t *testing.T) {
+	t.Parallel()
+
+	Check(t, func(t *T) {
+		lo := Int32Range(0, unicode.MaxRune).Draw(t, "lo")
+		hi := Int32Range(lo, unicode.MaxRune).Draw(t, "hi")
+		exLo := Int32Range(lo, hi).Draw(t, "exLo")
+		exHi := Int32Range(exLo, hi).Draw(t, "exHi")
+		ex := Int32Range(lo, hi).Draw(t, "ex")
+
+		n := hi - lo + 1 - (exHi - exLo + 1)
+		if ex < exLo || ex > exHi {
+			n--
+		}
+		if n == 0 {
+			t.Skip("empty set")
+		}
+
+		set := RuneSet{}.Range(lo, hi).ExceptRange(exLo, exHi).Except(ex)
+		r := RuneFromSet(set).Draw(t, "r")
+		if r < lo || r > hi {
+			t.Fatalf("got rune %q outside of range [%q, %q]", r, lo, hi)
+		}
+		if r >= exLo && r <= exHi || r == ex {
+			t.Fatalf("got excluded rune %q", r)
+		}
+	})
+}
+
+func TestRuneFromSetStrided(t *testing.T) {
+	t.Parallel()
+
+	table := &unicode.RangeTable{R16: []unicode.Range16{{Lo: 0, Hi: 100, Stride: 10}}}
+	gen := RuneFromSet(RuneSet{}.Tables(table).ExceptRange(3, 4).Except(7)) // both exclusions are between 0 and 10
+
+	seen := map[rune]bool{}
+	for i := 0; i < 1000; i++ {
+		seen[gen.Example(i)] = true
+	}
+	for r := rune(0); r <= 100; r += 10 {
+		if !seen[r] {
+			t.Errorf("rune %v has not been generated", r)
+		}
+	}
+	if len(seen) != 11 {
+		t.Errorf("got %v distinct runes instead of 11", len(seen))
+	}
+}
+
+func TestRuneFromSetTables(t *testing.T) {
+	t.Parallel()
+
+	gens := []struct {
+		gen *Generator[rune]
+		ok  func(rune) bool
+	}{
+		{RuneFromSet(RuneSetNoControl()), func(r rune) bool { return !unicode.IsControl(r) }},
+		{RuneFromSet(RuneSetPrintableASCII()), func(r rune) bool { return r <= unicode.MaxASCII && unicode.IsPrint(r) }},
+		{RuneFromSet(RuneSetIdentStart()), func(r rune) bool { return r == '_' || unicode.IsLetter(r) }},
+		{RuneFromSet(RuneSetIdentContinue()), func(r rune) bool { return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) }},
+		{RuneFromSet(RuneSetIdentStart().Except('_').ExceptTables(unicode.Lu, unicode.Lt)), func(r rune) bool { return unicode.IsLetter(r) && !unicode.IsUpper(r) && !unicode.IsTitle(r) }},
+		{RuneFromSet(RuneSet{}.Runes('A', 'B', 'C').Tables(unicode.Greek).Except('B').ExceptTables(unicode.Lower)), func(r rune) bool { return r == 'A' || r == 'C' || unicode.Is(unicode.Greek, r) && !unicode.IsLower(r) }},
+	}
+
+	for _, g := range gens {
+		t.Run(g.gen.String(), MakeCheck(func(t *T) {
+			r := g.gen.Draw(t, "r")
+			if !g.ok(r) {
+				t.Fatalf("got unexpected rune %q", r)
+			}
+		}))
+	}
+}
+
+func TestRuneFromSetEmpty(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("no panic for an empty rune set")
+		}
+	}()
+
+	RuneFromSet(RuneSet{}.Runes('a').Range('0', '9').ExceptRange('0', 'z'))
+}
@@ -0,0 +1,103 @@
+// Copyright 2019 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rapid
+
+import (
+	"math"
+	"math/bits"
+)
+
+const targetPadding = 64 // random data appended to mutated test cases to avoid overruns
+
+// Target asks rapid to search for test cases which maximize score,
+// with label identifying the score to maximize (e.g. "queue depth").
+// After the usual checks, rapid runs as many more test cases, mutating
+// the ones with the best scores found so far. This helps to find
+// worst cases that random generation is unlikely to hit.
+//
+// The best score for each label is reported at the end of [Check],
+// and is available in [Stats.Targets].
+// Calling Target several times with the same label in one test case
+// has the same effect as calling it once with the maximum score.
+func (t *T) Target(score float64, label string) {
+	assertf(!math.IsNaN(score), "target score for %q should not be a NaN", label)
+
+	if t.stats != nil {
+		t.stats.target(score, label)
+	}
+}
+
+type target struct {
+	score float64
+	buf   []uint64
+}
+
+func (c *caseStats) target(score float64, label string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.targets == nil {
+		c.targets = map[string]float64{}
+	}
+	if prev, ok := c.targets[label]; !ok || score > prev {
+		c.targets[label] = score
+	}
+}
+
+func (c *caseStats) hasTargets() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.targets) > 0
+}
+
+// updateTargets remembers buf for every label c has at least as good score as before.
+// Accepting equal scores lets hill climbing move along plateaus.
+func (s *checkStats) updateTargets(c *caseStats, buf []uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for label, score := range c.targets {
+		if tgt, ok := s.targets[label]; !ok || score >= tgt.score {
+			s.targets[label] = &target{score: score, buf: buf}
+		}
+	}
+}
+
+// mutateTarget picks a label based on i, and returns a mutation of its best test case.
+func (s *checkStats) mutateTarget(ctx *jsf64ctx, i int) []uint64 {
+	labels := sortedKeys(s.targets)
+	return mutate(ctx, s.targets[labels[i%len(labels)]].buf)
+}
+
+func mutate(ctx *jsf64ctx, buf []uint64) []uint64 {
+	m := make([]uint64, len(buf), len(buf)+targetPadding)
+	copy(m, buf)
+
+	if len(m) > 0 {
+		i := int(ctx.rand() % uint64(len(m)))
+		delta := 1 + ctx.rand()&bitmask64(uint(ctx.rand()%uint64(bits.Len64(m[i])+1)))
+		switch ctx.rand() % 4 {
+		case 0:
+			m[i] += delta
+		case 1:
+			if m[i] >= delta {
+				m[i] -= delta
+			}
+		case 2:
+			m[i] ^= 1 << (ctx.rand() % 64)
+		default:
+			m[i] = ctx.rand()
+		}
+	}
+
+	for len(m) < cap(m) {
+		m = append(m, ctx.rand())
+	}
+
+	return m
+}
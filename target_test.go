@@ -0,0 +1,110 @@
+// Copyright 2019 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rapid
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestTarget(t *testing.T) {
+	t.Parallel()
+
+	cfg := flags
+	cfg.checks = 50
+	cfg.noshrink = true // record every test case
+
+	best := math.Inf(-1)
+	prop := func(t *T) {
+		n := IntRange(0, 1<<20).Draw(t, "n")
+		m := IntRange(0, 1<<20).Draw(t, "m")
+		score := -math.Abs(float64(n - m))
+		best = math.Max(best, score)
+		t.Target(score, "diff")
+	}
+
+	st := newCheckStats()
+	valid, _, _, failures := findBug(t, checkDeadline(nil), &cfg, baseSeed(), st, prop)
+	if len(failures) != 0 {
+		t.Fatalf("unexpected failures: %v", failures)
+	}
+	if valid != cfg.checks*2 {
+		t.Errorf("got %v valid test cases instead of %v", valid, cfg.checks*2)
+	}
+	if st.valid != cfg.checks {
+		t.Errorf("got %v test cases in stats instead of %v", st.valid, cfg.checks)
+	}
+
+	tgt := st.targets["diff"]
+	if tgt == nil || tgt.score != best {
+		t.Fatalf("got best target %v instead of %v", tgt, best)
+	}
+	c := &caseStats{}
+	tt := newT(t, newBufBitStream(tgt.buf, false), false, nil)
+	tt.stats = c
+	if err := checkOnce(tt, prop); err != nil || c.targets["diff"] != best {
+		t.Errorf("best test case replayed with score %v (%v) instead of %v", c.targets["diff"], err, best)
+	}
+}
+
+func TestTarget_Single(t *testing.T) {
+	t.Parallel()
+
+	cfg := flags
+	cfg.checks = 10
+
+	var seen []string // the target is reached only by the second distinct test case
+	prop := func(t *T) {
+		s := fmt.Sprint(SliceOfN(Uint64(), 8, 8).Draw(t, "s"))
+		if len(seen) < 2 && (len(seen) == 0 || s != seen[0]) {
+			seen = append(seen, s)
+		}
+		if len(seen) == 2 && s == seen[1] {
+			t.Target(1, "second")
+		}
+	}
+
+	st := newCheckStats()
+	valid, _, _, failures := findBug(t, checkDeadline(nil), &cfg, baseSeed(), st, prop)
+	if len(failures) != 0 {
+		t.Fatalf("unexpected failures: %v", failures)
+	}
+	if st.targets["second"] == nil || valid != cfg.checks*2 {
+		t.Errorf("target reached by a single test case has not been mutated: got %v valid test cases instead of %v", valid, cfg.checks*2)
+	}
+}
+
+func TestTarget_Failure(t *testing.T) {
+	t.Parallel()
+
+	cfg := flags
+	cfg.checks = 50
+	cfg.failfile = ""
+
+	runs := 0
+	prop := func(t *T) {
+		runs++
+		n := IntRange(0, 1000).Draw(t, "n")
+		t.Target(float64(n), "n")
+		if runs > cfg.checks+1 && n > 0 {
+			t.Fatalf("failed while targeting")
+		}
+	}
+
+	_, _, _, failures := doCheck(t, checkDeadline(nil), &cfg, baseSeed(), nil, newCheckStats(), prop)
+	if len(failures) != 1 {
+		t.Fatalf("got %v failures instead of 1", len(failures))
+	}
+	f := failures[0]
+	if f.seed != 0 || !sameError(f.err1, f.err2) {
+		t.Errorf("unexpected failure from targeting: seed %v, %v vs %v", f.seed, f.err1, f.err2)
+	}
+	if len(f.buf) == 0 {
+		t.Errorf("failure has no data to reproduce it")
+	}
+}
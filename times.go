@@ -0,0 +1,291 @@
+// Copyright 2019 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rapid
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	timeEdgeCaseProb = 0.15
+	timeFracProb     = 0.5
+	fixedZoneProb    = 0.25
+
+	minFixedZoneOffset = -12 * 60 // in minutes
+	maxFixedZoneOffset = 14 * 60
+)
+
+var (
+	// UTC must be the first one, for locations to shrink towards it.
+	// Offsets (standard time, in minutes) are used when the system has no time zone database.
+	zoneNames = []struct {
+		name   string
+		offset int
+	}{
+		{"UTC", 0},
+		{"America/New_York", -5 * 60},
+		{"America/Los_Angeles", -8 * 60},
+		{"America/Sao_Paulo", -3 * 60},
+		{"America/St_Johns", -3*60 - 30},
+		{"Europe/London", 0},
+		{"Europe/Berlin", 1 * 60},
+		{"Europe/Moscow", 3 * 60},
+		{"Africa/Casablanca", 1 * 60},
+		{"Asia/Kolkata", 5*60 + 30},
+		{"Asia/Kathmandu", 5*60 + 45},
+		{"Asia/Shanghai", 8 * 60},
+		{"Asia/Tokyo", 9 * 60},
+		{"Australia/Sydney", 10 * 60},
+		{"Australia/Lord_Howe", 10*60 + 30},
+		{"Pacific/Auckland", 12 * 60},
+		{"Pacific/Chatham", 12*60 + 45},
+		{"Pacific/Apia", 13 * 60},
+		{"Pacific/Kiritimati", 14 * 60},
+		{"Pacific/Pago_Pago", -11 * 60},
+		{"Antarctica/Troll", 0},
+	}
+
+	zones = sync.OnceValue(func() []*time.Location {
+		locs := make([]*time.Location, len(zoneNames))
+		for i, z := range zoneNames {
+			loc, err := time.LoadLocation(z.name)
+			if err != nil {
+				loc = time.FixedZone(z.name, z.offset*60)
+			}
+			locs[i] = loc
+		}
+		return locs
+	})
+
+	durationEdgeCases = []time.Duration{
+		0,
+		time.Nanosecond, -time.Nanosecond,
+		time.Microsecond, -time.Microsecond,
+		time.Millisecond, -time.Millisecond,
+		time.Second, -time.Second,
+		time.Minute, -time.Minute,
+		time.Hour, -time.Hour,
+		24 * time.Hour, -24 * time.Hour,
+		math.MinInt64, math.MaxInt64,
+	}
+
+	timeEdgeCases = []time.Time{
+		time.Unix(0, 0),
+		time.Unix(0, -1),
+		time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Unix(math.MaxInt32, 0),
+		time.Unix(math.MaxInt32+1, 0),
+		time.Unix(math.MinInt32, 0),
+		time.Unix(math.MaxUint32, 0),
+	}
+
+	// Go does not represent leap seconds; times around them are still a common source of bugs.
+	leapSeconds = []time.Time{
+		time.Date(1972, 6, 30, 23, 59, 59, 0, time.UTC),
+		time.Date(1972, 12, 31, 23, 59, 59, 0, time.UTC),
+		time.Date(1998, 12, 31, 23, 59, 59, 0, time.UTC),
+		time.Date(2005, 12, 31, 23, 59, 59, 0, time.UTC),
+		time.Date(2008, 12, 31, 23, 59, 59, 0, time.UTC),
+		time.Date(2012, 6, 30, 23, 59, 59, 0, time.UTC),
+		time.Date(2015, 6, 30, 23, 59, 59, 0, time.UTC),
+		time.Date(2016, 12, 31, 23, 59, 59, 0, time.UTC),
+	}
+)
+
+// Duration is a shorthand for [DurationRange]([math.MinInt64], [math.MaxInt64]).
+func Duration() *Generator[time.Duration] {
+	return DurationRange(math.MinInt64, math.MaxInt64)
+}
+
+// DurationRange creates a generator of durations in range [min, max].
+// Generated durations are biased towards edge cases like common units
+// and range boundaries, and shrink towards zero.
+func DurationRange(min time.Duration, max time.Duration) *Generator[time.Duration] {
+	assertf(min <= max, "invalid range [%v, %v]", min, max)
+
+	return newGenerator[time.Duration](&durationGen{
+		min: min,
+		max: max,
+	})
+}
+
+type durationGen struct {
+	min time.Duration
+	max time.Duration
+}
+
+func (g *durationGen) String() string {
+	if g.min == math.MinInt64 && g.max == math.MaxInt64 {
+		return "Duration()"
+	}
+
+	return fmt.Sprintf("DurationRange(%v, %v)", g.min, g.max)
+}
+
+func (g *durationGen) value(t *T) time.Duration {
+	i, _, _ := genIntRange(t.s, int64(g.min), int64(g.max), true)
+	d := time.Duration(i)
+
+	if flipBiasedCoin(t.s, timeEdgeCaseProb) {
+		edges := []time.Duration{g.min}
+		for _, e := range durationEdgeCases {
+			if e > g.min && e < g.max {
+				edges = append(edges, e)
+			}
+		}
+		edges = append(edges, g.max)
+		slices.Sort(edges)
+
+		// snapping to the nearest edge case (instead of choosing a random one) keeps the value shrinkable
+		j, _ := slices.BinarySearch(edges, d)
+		if j == len(edges) || (j > 0 && uint64(d-edges[j-1]) <= uint64(edges[j]-d)) {
+			j--
+		}
+		d = edges[j]
+	}
+
+	return d
+}
+
+// Time creates a generator of times in locations generated by [Location],
+// with years in range [0, 9999] in the time's location.
+// Generated times are biased towards edge cases like the Unix epoch,
+// boundaries of the year range, leap seconds and time zone transitions,
+// and shrink towards the Unix epoch in UTC.
+// Generated times never have a monotonic clock reading.
+func Time() *Generator[time.Time] {
+	return newGenerator[time.Time](&timeGen{})
+}
+
+// TimeRange creates a generator of times in range [min, max] in locations generated by [Location].
+// Generated times are biased in the same way as with [Time], and shrink towards the Unix epoch
+// (or the closest range boundary, if the epoch is outside of the range).
+func TimeRange(min time.Time, max time.Time) *Generator[time.Time] {
+	assertf(!min.After(max), "invalid range [%v, %v]", min, max)
+
+	return newGenerator[time.Time](&timeGen{
+		min:      min,
+		max:      max,
+		hasRange: true,
+	})
+}
+
+type timeGen struct {
+	min      time.Time
+	max      time.Time
+	hasRange bool
+}
+
+func (g *timeGen) String() string {
+	if !g.hasRange {
+		return "Time()"
+	}
+
+	return fmt.Sprintf("TimeRange(%v, %v)", g.min.Format(time.RFC3339Nano), g.max.Format(time.RFC3339Nano))
+}
+
+func (g *timeGen) value(t *T) time.Time {
+	loc := genLocation(t.s)
+
+	min, max := g.min, g.max
+	if !g.hasRange {
+		min = time.Date(0, 1, 1, 0, 0, 0, 0, loc)
+		max = time.Date(9999, 12, 31, 23, 59, 59, 999999999, loc)
+	}
+
+	// drawing the year first spreads times over the whole range, instead of
+	// clustering them around the epoch like a biased number of seconds would
+	year, _, _ := genIntRange(t.s, int64(min.In(loc).Year()-1970), int64(max.In(loc).Year()-1970), true)
+	yearStart := time.Date(1970+int(year), 1, 1, 0, 0, 0, 0, loc)
+	if yearStart.Before(min) {
+		yearStart = min
+	}
+	yearEnd := time.Date(1970+int(year)+1, 1, 1, 0, 0, 0, -1, loc)
+	if yearEnd.After(max) {
+		yearEnd = max
+	}
+
+	minSec, maxSec := yearStart.Unix(), yearEnd.Unix()
+	sec, _, _ := genIntRange(t.s, minSec, maxSec, true)
+	minNsec, maxNsec := 0, 999999999
+	if sec == minSec {
+		minNsec = yearStart.Nanosecond()
+	}
+	if sec == maxSec {
+		maxNsec = yearEnd.Nanosecond()
+	}
+	nsec := int64(minNsec)
+	if flipBiasedCoin(t.s, timeFracProb) {
+		nsec, _, _ = genIntRange(t.s, int64(minNsec), int64(maxNsec), true)
+	}
+	tm := time.Unix(sec, nsec).In(loc)
+
+	if flipBiasedCoin(t.s, timeEdgeCaseProb) {
+		edges := []time.Time{min, max}
+		candidates := append([]time.Time{}, timeEdgeCases...)
+		for _, ls := range leapSeconds {
+			candidates = append(candidates, ls, ls.Add(time.Second))
+		}
+		start, end := tm.ZoneBounds()
+		for _, tr := range []time.Time{start, end} {
+			if !tr.IsZero() {
+				candidates = append(candidates, tr, tr.Add(-1))
+			}
+		}
+		for _, c := range candidates {
+			if c.After(min) && c.Before(max) {
+				edges = append(edges, c)
+			}
+		}
+		slices.SortFunc(edges, time.Time.Compare)
+
+		// snap to the nearest edge case, see durationGen.value
+		j, _ := slices.BinarySearchFunc(edges, tm, time.Time.Compare)
+		if j == len(edges) || (j > 0 && tm.Sub(edges[j-1]) <= edges[j].Sub(tm)) {
+			j--
+		}
+		tm = edges[j]
+	}
+
+	return tm.In(loc)
+}
+
+// Location creates a generator of time zones. Generated locations are either
+// well-known zones with interesting offsets and daylight saving time rules
+// (loaded with [time.LoadLocation], or replaced by their standard offset from UTC
+// when the time zone database is not available), or fixed offsets from UTC
+// created by [time.FixedZone]. Locations shrink towards [time.UTC].
+func Location() *Generator[*time.Location] {
+	return newGenerator[*time.Location](&locationGen{})
+}
+
+type locationGen struct{}
+
+func (g *locationGen) String() string {
+	return "Location()"
+}
+
+func (g *locationGen) value(t *T) *time.Location {
+	return genLocation(t.s)
+}
+
+func genLocation(s bitStream) *time.Location {
+	if flipBiasedCoin(s, fixedZoneProb) {
+		offset, _, _ := genIntRange(s, minFixedZoneOffset, maxFixedZoneOffset, true)
+		if offset == 0 {
+			return time.UTC
+		}
+		return time.FixedZone("", int(offset)*60)
+	}
+
+	locs := zones()
+	return locs[genIndex(s, len(locs), true)]
+}
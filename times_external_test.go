@@ -0,0 +1,136 @@
+// Copyright 2019 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rapid_test
+
+import (
+	"testing"
+	"time"
+
+	. "pgregory.net/rapid"
+)
+
+func TestTimeExamples(t *testing.T) {
+	gens := []*Generator[time.Time]{
+		Time(),
+		TimeRange(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)),
+	}
+
+	for _, g := range gens {
+		t.Run(g.String(), func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				t.Log(g.Example().Format(time.RFC3339Nano))
+			}
+		})
+	}
+}
+
+func TestTime(t *testing.T) {
+	t.Parallel()
+
+	Check(t, func(t *T) {
+		tm := Time().Draw(t, "t")
+		if y := tm.Year(); y < 0 || y > 9999 {
+			t.Fatalf("got time %v with year %v outside of [0, 9999]", tm, y)
+		}
+		if _, err := tm.MarshalText(); err != nil {
+			t.Fatalf("got time %v which can not be marshaled: %v", tm, err)
+		}
+		if !tm.Equal(tm.Round(0)) || tm != tm.Round(0) {
+			t.Fatalf("got time %v with a monotonic clock reading", tm)
+		}
+	})
+}
+
+func TestTimeRange(t *testing.T) {
+	t.Parallel()
+
+	Check(t, func(t *T) {
+		min := Time().Draw(t, "min")
+		max := Time().Draw(t, "max")
+		if min.After(max) {
+			min, max = max, min
+		}
+
+		tm := TimeRange(min, max).Draw(t, "t")
+		if tm.Before(min) || tm.After(max) {
+			t.Fatalf("got time %v outside of [%v, %v]", tm, min, max)
+		}
+	})
+}
+
+func TestTimeEdgeCases(t *testing.T) {
+	t.Parallel()
+
+	epoch := time.Unix(0, 0)
+	g := TimeRange(epoch.Add(-time.Hour), time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	var gotEpoch, gotLeap, gotTransition bool
+	for i := 0; i < 10000 && !(gotEpoch && gotLeap && gotTransition); i++ {
+		tm := g.Example(i)
+		gotEpoch = gotEpoch || tm.Equal(epoch)
+		gotLeap = gotLeap || tm.Equal(time.Date(2016, 12, 31, 23, 59, 59, 0, time.UTC))
+		start, _ := tm.ZoneBounds()
+		gotTransition = gotTransition || (tm.Year() > 1970 && start.Equal(tm))
+	}
+	if !gotEpoch || !gotLeap || !gotTransition {
+		t.Fatalf("edge cases not generated: epoch %v, leap second %v, zone transition %v", gotEpoch, gotLeap, gotTransition)
+	}
+}
+
+func TestDurationRange(t *testing.T) {
+	t.Parallel()
+
+	Check(t, func(t *T) {
+		min := Duration().Draw(t, "min")
+		max := Duration().Draw(t, "max")
+		if min > max {
+			min, max = max, min
+		}
+
+		d := DurationRange(min, max).Draw(t, "d")
+		if d < min || d > max {
+			t.Fatalf("got duration %v outside of [%v, %v]", d, min, max)
+		}
+	})
+}
+
+func TestLocation(t *testing.T) {
+	t.Parallel()
+
+	names := map[string]bool{}
+	Check(t, func(t *T) {
+		loc := Location().Draw(t, "loc")
+		if loc == nil {
+			t.Fatalf("got nil location")
+		}
+		names[loc.String()] = true
+	})
+
+	if !names["UTC"] || len(names) < 5 {
+		t.Fatalf("got too few distinct locations: %v", names)
+	}
+}
+
+func TestMakeTime(t *testing.T) {
+	t.Parallel()
+
+	type event struct {
+		At       time.Time
+		Duration time.Duration
+		Zone     *time.Location
+	}
+
+	Check(t, func(t *T) {
+		e := Make[event]().Draw(t, "e")
+		if e.Zone == nil {
+			t.Fatalf("got nil location")
+		}
+		if y := e.At.Year(); y < 0 || y > 9999 {
+			t.Fatalf("got time %v with year %v outside of [0, 9999]", e.At, y)
+		}
+	})
+}
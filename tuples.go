@@ -0,0 +1,125 @@
+// Copyright 2019 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rapid
+
+import "fmt"
+
+// Tuple2 is a pair of values. Tuples of comparable values are comparable, and can be used
+// with [SliceOfDistinct] and [ID] to generate distinct pairs.
+type Tuple2[A any, B any] struct {
+	First  A
+	Second B
+}
+
+// Tuple3 is a triple of values. Tuples of comparable values are comparable, and can be used
+// with [SliceOfDistinct] and [ID] to generate distinct triples.
+type Tuple3[A any, B any, C any] struct {
+	First  A
+	Second B
+	Third  C
+}
+
+// Zip2 creates a generator of pairs of values produced by a and b.
+func Zip2[A any, B any](a *Generator[A], b *Generator[B]) *Generator[Tuple2[A, B]] {
+	return newGenerator[Tuple2[A, B]](&tuple2Gen[A, B]{
+		a: a,
+		b: b,
+	})
+}
+
+type tuple2Gen[A any, B any] struct {
+	a *Generator[A]
+	b *Generator[B]
+}
+
+func (g *tuple2Gen[A, B]) String() string {
+	return fmt.Sprintf("Zip2(%v, %v)", g.a, g.b)
+}
+
+func (g *tuple2Gen[A, B]) value(t *T) Tuple2[A, B] {
+	return Tuple2[A, B]{
+		First:  g.a.value(t),
+		Second: g.b.value(t),
+	}
+}
+
+// Zip3 creates a generator of triples of values produced by a, b and c.
+func Zip3[A any, B any, C any](a *Generator[A], b *Generator[B], c *Generator[C]) *Generator[Tuple3[A, B, C]] {
+	return newGenerator[Tuple3[A, B, C]](&tuple3Gen[A, B, C]{
+		a: a,
+		b: b,
+		c: c,
+	})
+}
+
+type tuple3Gen[A any, B any, C any] struct {
+	a *Generator[A]
+	b *Generator[B]
+	c *Generator[C]
+}
+
+func (g *tuple3Gen[A, B, C]) String() string {
+	return fmt.Sprintf("Zip3(%v, %v, %v)", g.a, g.b, g.c)
+}
+
+func (g *tuple3Gen[A, B, C]) value(t *T) Tuple3[A, B, C] {
+	return Tuple3[A, B, C]{
+		First:  g.a.value(t),
+		Second: g.b.value(t),
+		Third:  g.c.value(t),
+	}
+}
+
+// Unzip2 splits a slice of pairs into slices of first and second values.
+func Unzip2[A any, B any](s []Tuple2[A, B]) ([]A, []B) {
+	as, bs := make([]A, len(s)), make([]B, len(s))
+	for i, t := range s {
+		as[i], bs[i] = t.First, t.Second
+	}
+
+	return as, bs
+}
+
+// Unzip3 splits a slice of triples into slices of first, second and third values.
+func Unzip3[A any, B any, C any](s []Tuple3[A, B, C]) ([]A, []B, []C) {
+	as, bs, cs := make([]A, len(s)), make([]B, len(s)), make([]C, len(s))
+	for i, t := range s {
+		as[i], bs[i], cs[i] = t.First, t.Second, t.Third
+	}
+
+	return as, bs, cs
+}
+
+// Tuple2First returns the first value of a pair. It can be used as a key function
+// with [SliceOfDistinct] to generate pairs with distinct first values.
+func Tuple2First[A any, B any](t Tuple2[A, B]) A {
+	return t.First
+}
+
+// Tuple2Second returns the second value of a pair. It can be used as a key function
+// with [SliceOfDistinct] to generate pairs with distinct second values.
+func Tuple2Second[A any, B any](t Tuple2[A, B]) B {
+	return t.Second
+}
+
+// Tuple3First returns the first value of a triple. It can be used as a key function
+// with [SliceOfDistinct] to generate triples with distinct first values.
+func Tuple3First[A any, B any, C any](t Tuple3[A, B, C]) A {
+	return t.First
+}
+
+// Tuple3Second returns the second value of a triple. It can be used as a key function
+// with [SliceOfDistinct] to generate triples with distinct second values.
+func Tuple3Second[A any, B any, C any](t Tuple3[A, B, C]) B {
+	return t.Second
+}
+
+// Tuple3Third returns the third value of a triple. It can be used as a key function
+// with [SliceOfDistinct] to generate triples with distinct third values.
+func Tuple3Third[A any, B any, C any](t Tuple3[A, B, C]) C {
+	return t.Third
+}
@@ -0,0 +1,85 @@
+// Copyright 2019 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rapid_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "pgregory.net/rapid"
+)
+
+func TestZip2(t *testing.T) {
+	t.Parallel()
+
+	g := Zip2(IntRange(0, 9), StringN(1, 1, -1))
+
+	Check(t, func(t *T) {
+		p := g.Draw(t, "p")
+		if p.First < 0 || p.First > 9 || len([]rune(p.Second)) != 1 {
+			t.Fatalf("got impossible %#v", p)
+		}
+	})
+}
+
+func TestZip3DistinctUnzip(t *testing.T) {
+	t.Parallel()
+
+	g := SliceOfDistinct(Zip3(IntRange(0, 2), IntRange(0, 2), Bool()), ID)
+
+	Check(t, func(t *T) {
+		s := g.Draw(t, "s")
+		as, bs, cs := Unzip3(s)
+		seen := map[string]bool{}
+		for i := range s {
+			if as[i] != s[i].First || bs[i] != s[i].Second || cs[i] != s[i].Third {
+				t.Fatalf("Unzip3 mismatch at %v", i)
+			}
+			key := fmt.Sprint(as[i], bs[i], cs[i])
+			if seen[key] {
+				t.Fatalf("duplicate %v in %#v", key, s)
+			}
+			seen[key] = true
+		}
+	})
+}
+
+func TestUnzip2(t *testing.T) {
+	t.Parallel()
+
+	as, bs := Unzip2([]Tuple2[int, string]{{1, "a"}, {2, "b"}})
+	if fmt.Sprint(as, bs) != "[1 2] [a b]" {
+		t.Fatalf("got %v %v", as, bs)
+	}
+}
+
+func TestTupleKeys(t *testing.T) {
+	t.Parallel()
+
+	g2 := SliceOfDistinct(Zip2(IntRange(0, 3), Int()), Tuple2First[int, int])
+	g3 := SliceOfDistinct(Zip3(Int(), Int(), IntRange(0, 3)), Tuple3Third[int, int, int])
+
+	Check(t, func(t *T) {
+		s2 := g2.Draw(t, "s2")
+		firsts := map[int]bool{}
+		for _, p := range s2 {
+			if firsts[p.First] {
+				t.Fatalf("duplicate first value %v in %#v", p.First, s2)
+			}
+			firsts[p.First] = true
+		}
+
+		s3 := g3.Draw(t, "s3")
+		thirds := map[int]bool{}
+		for _, p := range s3 {
+			if thirds[p.Third] {
+				t.Fatalf("duplicate third value %v in %#v", p.Third, s3)
+			}
+			thirds[p.Third] = true
+		}
+	})
+}
@@ -9,6 +9,7 @@ package rapid
 import (
 	"math"
 	"math/bits"
+	"sort"
 )
 
 const (
@@ -158,7 +159,7 @@ func flipBiasedCoin(s bitStream, p float64) bool {
 }
 
 type loadedDie struct {
-	table []int
+	ends []int // ends[i] is the total weight of faces [0, i]
 }
 
 func newLoadedDie(weights []int) *loadedDie {
@@ -166,35 +167,29 @@ func newLoadedDie(weights []int) *loadedDie {
 
 	if len(weights) == 1 {
 		return &loadedDie{
-			table: []int{0},
+			ends: []int{1},
 		}
 	}
 
+	ends := make([]int, len(weights))
 	total := 0
-	for _, w := range weights {
-		assert(w > 0 && w < 100)
+	for i, w := range weights {
+		assertf(w > 0 && w <= math.MaxInt-total, "invalid weight %v", w)
 		total += w
-	}
-
-	table := make([]int, total)
-	i := 0
-	for n, w := range weights {
-		for j := i; i < j+w; i++ {
-			table[i] = n
-		}
+		ends[i] = total
 	}
 
 	return &loadedDie{
-		table: table,
+		ends: ends,
 	}
 }
 
 func (d *loadedDie) roll(s bitStream) int {
 	i := s.beginGroup(dieRollLabel, false)
-	ix := genIndex(s, len(d.table), false)
+	ix := genIndex(s, d.ends[len(d.ends)-1], false)
 	s.endGroup(i, false)
 
-	return d.table[ix]
+	return sort.SearchInts(d.ends, ix+1)
 }
 
 type repeat struct {
@@ -236,6 +231,11 @@ func (r *repeat) avg() int {
 }
 
 func (r *repeat) more(s bitStream) bool {
+	return r.moreWith(s, func(p float64) bool { return flipBiasedCoin(s, p) })
+}
+
+// moreWith is like more, but uses flip to decide to continue with probability p.
+func (r *repeat) moreWith(s bitStream, flip func(p float64) bool) bool {
 	if r.group >= 0 {
 		s.endGroup(r.group, r.rejected)
 	}
@@ -250,7 +250,7 @@ func (r *repeat) more(s bitStream) bool {
 		pCont = 0
 	}
 
-	cont := flipBiasedCoin(s, pCont)
+	cont := flip(pCont)
 	if cont {
 		r.count++
 	} else {
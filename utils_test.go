@@ -264,6 +264,7 @@ func TestLoadedDie(t *testing.T) {
 		{1, 2},
 		{3, 2, 1},
 		{1, 2, 4, 2, 1},
+		{1000, 1, 250},
 	}
 
 	for _, ws := range weights {